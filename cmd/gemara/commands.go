@@ -0,0 +1,376 @@
+package main
+
+import (
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	oscalTypes "github.com/defenseunicorns/go-oscal/src/types/oscal-1-1-3"
+
+	"github.com/ossf/gemara/dsse"
+	"github.com/ossf/gemara/layer1"
+	"github.com/ossf/gemara/layer2"
+	"github.com/ossf/gemara/layer4"
+	"github.com/ossf/gemara/render"
+)
+
+// stringList is a flag that may be repeated to collect multiple values.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func runValidate(args []string, env environment) error {
+	flags := newFlagSet("validate", "<file>...",
+		"Check the structure of documents against the Gemara types of their layer: values must decode into the types,\n"+
+			"objects and lists must be where the types expect them, required fields must be present and unknown fields\n"+
+			"are reported, except at the top level of Layer 4 results. Other constraints of the CUE schemas, such as\n"+
+			"patterns, enumerations and value ranges, are not checked; use cue vet with the schemas for those.", env)
+	layer := flags.Int("layer", 0, "layer of the documents (detected from their fields if not set)")
+	if err := parseFlags(flags, args, 1, -1); err != nil {
+		return err
+	}
+
+	failed := false
+	for _, source := range flags.Args() {
+		doc, err := loadDocument(source, *layer, env.stdin)
+		if err != nil {
+			_, _ = fmt.Fprintln(env.stderr, err)
+			failed = true
+			continue
+		}
+		errs := doc.checkStructure()
+		if len(errs) == 0 {
+			_, _ = fmt.Fprintf(env.stdout, "%s: structurally valid Layer %d document\n", source, doc.layer)
+			continue
+		}
+		failed = true
+		_, _ = fmt.Fprintf(env.stderr, "%s: structurally invalid Layer %d document\n", source, doc.layer)
+		for _, err := range errs {
+			_, _ = fmt.Fprintf(env.stderr, "  %v\n", err)
+		}
+	}
+	if failed {
+		return errFailed
+	}
+	return nil
+}
+
+func runOSCAL(args []string, env environment) error {
+	flags := newFlagSet("oscal", "<file>", "Convert a Layer 1 guidance document or Layer 2 catalog to OSCAL.", env)
+	layer := flags.Int("layer", 0, "layer of the document (detected from its fields if not set)")
+	model := flags.String("type", "catalog", "OSCAL model to produce for Layer 1 documents: catalog or profile")
+	format := flags.String("format", "json", "output format: json or yaml")
+	version := flags.String("version", "", "version of the OSCAL document (Layer 1, defaults to the document version)")
+	canonicalHref := flags.String("canonical-href", "", "format string for the canonical link, with the version as its argument (Layer 1)")
+	catalogHref := flags.String("catalog-href", "", "location of the OSCAL catalog imported by a profile (Layer 1 profiles)")
+	controlHref := flags.String("control-href", "", "format string for control links, with the version and control ID as its arguments (Layer 2)")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(flags.Arg(0), *layer, env.stdin)
+	if err != nil {
+		return err
+	}
+
+	var models oscalTypes.OscalModels
+	switch value := doc.value.(type) {
+	case *layer1.GuidanceDocument:
+		var opts []layer1.GenerateOption
+		if *version != "" {
+			opts = append(opts, layer1.WithVersion(*version))
+		}
+		if *canonicalHref != "" {
+			opts = append(opts, layer1.WithCanonicalHrefFormat(*canonicalHref))
+		}
+		switch *model {
+		case "catalog":
+			catalog, err := value.ToOSCALCatalog(opts...)
+			if err != nil {
+				return err
+			}
+			models.Catalog = &catalog
+		case "profile":
+			if *catalogHref == "" {
+				return fmt.Errorf("-catalog-href is required for profiles")
+			}
+			profile, err := value.ToOSCALProfile(*catalogHref, opts...)
+			if err != nil {
+				return err
+			}
+			models.Profile = &profile
+		default:
+			return fmt.Errorf("unsupported OSCAL model %q; expected catalog or profile", *model)
+		}
+	case *layer2.Catalog:
+		if *model != "catalog" {
+			return fmt.Errorf("Layer 2 catalogs can only be converted to OSCAL catalogs")
+		}
+		if *controlHref == "" {
+			return fmt.Errorf("-control-href is required for Layer 2 catalogs")
+		}
+		catalog, err := value.ToOSCAL(*controlHref)
+		if err != nil {
+			return err
+		}
+		models.Catalog = &catalog
+	default:
+		return fmt.Errorf("Layer %d documents cannot be converted to OSCAL", doc.layer)
+	}
+	return writeData(env.stdout, *format, models)
+}
+
+func runRender(args []string, env environment) error {
+	flags := newFlagSet("render", "<file>", "Render a document of any layer as Markdown or HTML.", env)
+	layer := flags.Int("layer", 0, "layer of the document (detected from its fields if not set)")
+	format := flags.String("format", "markdown", "output format: markdown or html")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(flags.Arg(0), *layer, env.stdin)
+	if err != nil {
+		return err
+	}
+	switch *format {
+	case "markdown":
+		return render.Markdown(env.stdout, doc.value)
+	case "html":
+		return render.HTML(env.stdout, doc.value)
+	default:
+		return fmt.Errorf("unsupported output format %q; expected markdown or html", *format)
+	}
+}
+
+// guidanceDiffReport is the output of the diff command for Layer 1 documents checked against catalogs.
+type guidanceDiffReport struct {
+	Diff           layer1.GuidanceDiff    `json:"diff" yaml:"diff"`
+	MappingImpacts []layer2.MappingImpact `json:"mapping-impacts" yaml:"mapping-impacts"`
+}
+
+func runDiff(args []string, env environment) error {
+	flags := newFlagSet("diff", "<old> <new>",
+		"Compare two versions of a Layer 1 guidance document or Layer 2 catalog, or two Layer 4 evaluation runs.", env)
+	layer := flags.Int("layer", 0, "layer of the documents (detected from their fields if not set)")
+	format := flags.String("format", "markdown", "output format: markdown, yaml or json")
+	var catalogs stringList
+	flags.Var(&catalogs, "catalog", "Layer 2 catalog to check for mappings affected by Layer 1 changes (may be repeated)")
+	referenceId := flags.String("reference-id", "", "ID used by the catalogs to reference the Layer 1 document (defaults to the document ID)")
+	failOnRegression := flags.Bool("fail-on-regression", false, "exit with status 1 if an evaluation run has newly failing results")
+	if err := parseFlags(flags, args, 2, 2); err != nil {
+		return err
+	}
+
+	previous, err := loadDocument(flags.Arg(0), *layer, env.stdin)
+	if err != nil {
+		return err
+	}
+	current, err := loadDocument(flags.Arg(1), previous.layer, env.stdin)
+	if err != nil {
+		return err
+	}
+
+	var result interface{}
+	switch old := previous.value.(type) {
+	case *layer1.GuidanceDocument:
+		diff := old.Diff(current.value.(*layer1.GuidanceDocument))
+		result = diff
+		if len(catalogs) > 0 {
+			report := guidanceDiffReport{Diff: diff}
+			for _, source := range catalogs {
+				catalog, err := loadDocument(source, 2, env.stdin)
+				if err != nil {
+					return err
+				}
+				report.MappingImpacts = append(report.MappingImpacts,
+					layer2.GuidanceImpacts(diff, *referenceId, *catalog.value.(*layer2.Catalog))...)
+			}
+			if *format == "markdown" {
+				if err := render.Markdown(env.stdout, diff); err != nil {
+					return err
+				}
+				return writeImpacts(env, report.MappingImpacts)
+			}
+			result = report
+		}
+	case *layer2.Catalog:
+		result = old.Diff(current.value.(*layer2.Catalog))
+	case *layer4.EvaluationResults:
+		comparison := layer4.Compare(old, current.value.(*layer4.EvaluationResults))
+		if err := writeDiff(env, *format, comparison); err != nil {
+			return err
+		}
+		if *failOnRegression && comparison.HasRegressions() {
+			_, _ = fmt.Fprintf(env.stderr, "gemara diff: %s\n", comparison.Summary())
+			return errFailed
+		}
+		return nil
+	default:
+		return fmt.Errorf("Layer %d documents cannot be compared", previous.layer)
+	}
+	return writeDiff(env, *format, result)
+}
+
+func writeDiff(env environment, format string, result interface{}) error {
+	if format == "markdown" {
+		return render.Markdown(env.stdout, result)
+	}
+	return writeData(env.stdout, format, result)
+}
+
+func writeImpacts(env environment, impacts []layer2.MappingImpact) error {
+	_, _ = fmt.Fprint(env.stdout, "\n## Affected Catalog Mappings\n\n")
+	if len(impacts) == 0 {
+		_, err := fmt.Fprintln(env.stdout, "No catalog mappings are affected.")
+		return err
+	}
+	_, _ = fmt.Fprintln(env.stdout, "| Catalog | Control | Guideline | Change |")
+	_, _ = fmt.Fprintln(env.stdout, "|---------|---------|-----------|--------|")
+	for _, impact := range impacts {
+		change := string(impact.Type)
+		if impact.NewGuidelineId != "" {
+			change += " to " + impact.NewGuidelineId
+		}
+		_, err := fmt.Fprintf(env.stdout, "| %s | %s | %s: %s | %s |\n",
+			impact.CatalogId, impact.ControlId, impact.ReferenceId, impact.GuidelineId, change)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runSummary(args []string, env environment) error {
+	flags := newFlagSet("summary", "<file>", "Summarise Layer 4 evaluation results by control family and applicability.", env)
+	catalogSource := flags.String("catalog", "", "Layer 2 catalog used to group controls into families")
+	format := flags.String("format", "terminal", "output format: terminal, markdown, yaml or json")
+	noColor := flags.Bool("no-color", os.Getenv("NO_COLOR") != "", "disable colors in terminal output")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	doc, err := loadDocument(flags.Arg(0), 4, env.stdin)
+	if err != nil {
+		return err
+	}
+	var catalog *layer2.Catalog
+	if *catalogSource != "" {
+		catalogDoc, err := loadDocument(*catalogSource, 2, env.stdin)
+		if err != nil {
+			return err
+		}
+		catalog = catalogDoc.value.(*layer2.Catalog)
+	}
+
+	summary := doc.value.(*layer4.EvaluationResults).Summarize(catalog)
+	switch *format {
+	case "terminal":
+		var opts []render.Option
+		if *noColor {
+			opts = append(opts, render.WithoutColor())
+		}
+		return render.Terminal(env.stdout, summary, opts...)
+	case "markdown":
+		return render.Markdown(env.stdout, summary)
+	default:
+		return writeData(env.stdout, *format, summary)
+	}
+}
+
+func runSign(args []string, env environment) error {
+	flags := newFlagSet("sign", "<file>", "Sign Layer 4 evaluation results in a DSSE envelope with an in-toto statement.", env)
+	keyFile := flags.String("key", "", "PEM encoded Ed25519, ECDSA or RSA private key (required)")
+	keyId := flags.String("key-id", "", "ID of the signing key to record in the envelope")
+	catalogSource := flags.String("catalog", "", "Layer 2 catalog the results were evaluated against (required)")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	if *keyFile == "" || *catalogSource == "" {
+		return fmt.Errorf("-key and -catalog are required")
+	}
+
+	keyData, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return fmt.Errorf("error reading key: %w", err)
+	}
+	signer, err := dsse.ParsePrivateKey(keyData)
+	if err != nil {
+		return err
+	}
+	doc, err := loadDocument(flags.Arg(0), 4, env.stdin)
+	if err != nil {
+		return err
+	}
+	catalog, err := loadDocument(*catalogSource, 2, env.stdin)
+	if err != nil {
+		return err
+	}
+
+	envelope, err := doc.value.(*layer4.EvaluationResults).Sign(catalog.value.(*layer2.Catalog), signer, layer4.WithKeyId(*keyId))
+	if err != nil {
+		return err
+	}
+	return writeData(env.stdout, "json", envelope)
+}
+
+func runVerify(args []string, env environment) error {
+	flags := newFlagSet("verify", "<envelope>", "Verify a DSSE envelope containing signed Layer 4 evaluation results.", env)
+	var keyFiles stringList
+	flags.Var(&keyFiles, "key", "PEM encoded public key trusted to sign results (required, may be repeated)")
+	output := flags.String("output", "", "write the verified results to this file as YAML")
+	if err := parseFlags(flags, args, 1, 1); err != nil {
+		return err
+	}
+	if len(keyFiles) == 0 {
+		return fmt.Errorf("at least one -key is required")
+	}
+
+	var keys []crypto.PublicKey
+	for _, keyFile := range keyFiles {
+		keyData, err := os.ReadFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("error reading key: %w", err)
+		}
+		key, err := dsse.ParsePublicKey(keyData)
+		if err != nil {
+			return fmt.Errorf("%s: %w", keyFile, err)
+		}
+		keys = append(keys, key)
+	}
+	data, err := readInput(flags.Arg(0), env.stdin)
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+	var envelope dsse.Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("error decoding envelope %s: %w", flags.Arg(0), err)
+	}
+
+	predicate, err := layer4.VerifyEvaluationResults(envelope, keys...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = file.Close()
+		}()
+		if err := writeData(file, "yaml", predicate.Results); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(env.stdout, "%s: verified evaluation results for catalog %s %s\n", flags.Arg(0), predicate.CatalogId, predicate.CatalogVersion)
+	return err
+}
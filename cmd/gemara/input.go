@@ -0,0 +1,253 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/ossf/gemara/layer1"
+	"github.com/ossf/gemara/layer2"
+	"github.com/ossf/gemara/layer3"
+	"github.com/ossf/gemara/layer4"
+)
+
+// document is a Gemara document loaded from a file, URL or standard input.
+type document struct {
+	source string
+	layer  int
+	// value is a pointer to the typed document, such as *layer2.Catalog
+	value interface{}
+	// raw is the generic decoded form of the document, used to check its structure
+	raw interface{}
+}
+
+// layerFields lists top-level fields that identify the layer of a document.
+var layerFields = []struct {
+	layer  int
+	fields []string
+}{
+	{4, []string{"evaluation-set"}},
+	{2, []string{"control-families", "threats", "capabilities", "imported-controls"}},
+	{3, []string{"contacts", "scope", "guidance-references", "control-references"}},
+	{1, []string{"categories", "principles", "imported-guidelines", "imported-principles", "front-matter"}},
+}
+
+// openTypes may contain fields that are not part of the schema, as evaluation tools
+// commonly add their own metadata to the results.
+var openTypes = map[reflect.Type]bool{
+	reflect.TypeOf(layer4.EvaluationResults{}): true,
+}
+
+// loadDocument reads and decodes a document. If layer is zero, it is detected from the document's fields.
+func loadDocument(source string, layer int, stdin io.Reader) (*document, error) {
+	data, err := readInput(source, stdin)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	doc := &document{source: source, layer: layer}
+	if err := yaml.Unmarshal(data, &doc.raw); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", source, err)
+	}
+	if doc.layer == 0 {
+		doc.layer, err = detectLayer(doc.raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+
+	switch doc.layer {
+	case 1:
+		doc.value = &layer1.GuidanceDocument{}
+	case 2:
+		doc.value = &layer2.Catalog{}
+	case 3:
+		doc.value = &layer3.PolicyDocument{}
+	case 4:
+		doc.value = &layer4.EvaluationResults{}
+	default:
+		return nil, fmt.Errorf("unsupported layer %d; expected 1, 2, 3 or 4", doc.layer)
+	}
+	if err := yaml.Unmarshal(data, doc.value); err != nil {
+		return nil, fmt.Errorf("error decoding %s as a Layer %d document: %w", source, doc.layer, err)
+	}
+	return doc, nil
+}
+
+// readInput reads a local file, an http(s) URL, or standard input if the source is "-".
+func readInput(source string, stdin io.Reader) ([]byte, error) {
+	if source == "-" {
+		return io.ReadAll(stdin)
+	}
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		resp, err := http.Get(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch URL: %w", err)
+		}
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("failed to fetch URL; response status: %v", resp.Status)
+		}
+		return io.ReadAll(resp.Body)
+	}
+	data, err := os.ReadFile(source)
+	if err != nil {
+		return nil, fmt.Errorf("error opening file: %w", err)
+	}
+	return data, nil
+}
+
+func detectLayer(raw interface{}) (int, error) {
+	fields, ok := raw.(map[string]interface{})
+	if !ok {
+		return 0, fmt.Errorf("expected a document object")
+	}
+	for _, candidate := range layerFields {
+		for _, field := range candidate.fields {
+			if _, found := fields[field]; found {
+				return candidate.layer, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unable to detect the document layer; set it with -layer")
+}
+
+// checkStructure checks that the document has the objects, lists and required fields of its Go type and no
+// unknown fields. It does not check the other constraints of the CUE schema, such as patterns and enumerations.
+func (d *document) checkStructure() []error {
+	return checkFields(d.raw, reflect.TypeOf(d.value), "")
+}
+
+// checkFields compares a decoded YAML node with the Go type it represents, using the
+// yaml struct tags of the generated types. Fields without omitempty are required.
+func checkFields(node interface{}, t reflect.Type, path string) []error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if node == nil {
+		return nil
+	}
+
+	var errs []error
+	switch t.Kind() {
+	case reflect.Struct:
+		fields, ok := node.(map[string]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: expected an object", displayPath(path))}
+		}
+		known := make(map[string]bool)
+		for _, field := range yamlFields(t) {
+			name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			known[name] = true
+			value, found := fields[name]
+			if !found {
+				if !strings.Contains(options, "omitempty") {
+					errs = append(errs, fmt.Errorf("%s: missing required field %q", displayPath(path), name))
+				}
+				continue
+			}
+			errs = append(errs, checkFields(value, field.Type, joinPath(path, name))...)
+		}
+		if !openTypes[t] {
+			for _, name := range sortedKeys(fields) {
+				if !known[name] {
+					errs = append(errs, fmt.Errorf("%s: unknown field %q", displayPath(path), name))
+				}
+			}
+		}
+	case reflect.Slice:
+		items, ok := node.([]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: expected a list", displayPath(path))}
+		}
+		for i, item := range items {
+			errs = append(errs, checkFields(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	case reflect.Map:
+		entries, ok := node.(map[string]interface{})
+		if !ok {
+			return []error{fmt.Errorf("%s: expected an object", displayPath(path))}
+		}
+		for _, key := range sortedKeys(entries) {
+			errs = append(errs, checkFields(entries[key], t.Elem(), joinPath(path, key))...)
+		}
+	}
+	return errs
+}
+
+// yamlFields returns the exported fields of a struct that have a yaml tag,
+// including the fields of embedded structs that are serialized inline.
+func yamlFields(t reflect.Type) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("yaml")
+		if !field.IsExported() || tag == "" || tag == "-" {
+			continue
+		}
+		if _, options, _ := strings.Cut(tag, ","); field.Anonymous && strings.Contains(options, "inline") {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			fields = append(fields, yamlFields(embedded)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "document"
+	}
+	return path
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeData writes the value as YAML or JSON.
+func writeData(w io.Writer, format string, value interface{}) error {
+	switch format {
+	case "yaml":
+		data, err := yaml.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("error encoding YAML: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case "json":
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error encoding JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	default:
+		return fmt.Errorf("unsupported output format %q; expected yaml or json", format)
+	}
+}
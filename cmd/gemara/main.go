@@ -0,0 +1,118 @@
+// Command gemara checks, converts, renders, diffs, summarises and signs Gemara documents.
+//
+// Usage:
+//
+//	gemara <command> [flags] <file>...
+//
+// Files may be local paths, http(s) URLs, or "-" to read from standard input.
+// The layer of each document is detected from its top-level fields unless set with -layer.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `Usage: gemara <command> [flags] <file>...
+
+Commands:
+  validate  Check the structure of documents of any layer
+  oscal     Convert a Layer 1 guidance document or Layer 2 catalog to OSCAL
+  render    Render a document as Markdown or HTML
+  diff      Compare two versions of a document or two evaluation runs
+  summary   Summarise Layer 4 evaluation results
+  sign      Sign Layer 4 evaluation results in a DSSE envelope
+  verify    Verify signed Layer 4 evaluation results
+
+Files may be local paths, http(s) URLs, or "-" for standard input.
+Run "gemara <command> -h" for the flags of each command.
+`
+
+// command is a subcommand of the CLI. It returns an error to be reported to the user.
+type command func(args []string, env environment) error
+
+var commands = map[string]command{
+	"validate": runValidate,
+	"oscal":    runOSCAL,
+	"render":   runRender,
+	"diff":     runDiff,
+	"summary":  runSummary,
+	"sign":     runSign,
+	"verify":   runVerify,
+}
+
+// environment holds the standard streams used by a command, so that commands can be tested.
+type environment struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// errFailed is returned by commands that have already reported their failures.
+var errFailed = errors.New("failed")
+
+func main() {
+	os.Exit(run(os.Args[1:], environment{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}))
+}
+
+func run(args []string, env environment) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" || args[0] == "help" {
+		_, _ = fmt.Fprint(env.stderr, usage)
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		_, _ = fmt.Fprintf(env.stderr, "gemara: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	err := cmd(args[1:], env)
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.Is(err, errUsage):
+		return 2
+	case errors.Is(err, errFailed):
+		return 1
+	default:
+		_, _ = fmt.Fprintf(env.stderr, "gemara %s: %v\n", args[0], err)
+		return 1
+	}
+}
+
+// errUsage is returned when a command is invoked with invalid flags or arguments.
+var errUsage = errors.New("invalid usage")
+
+// newFlagSet creates a flag set for a command that writes its usage to stderr.
+func newFlagSet(name, arguments, description string, env environment) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(env.stderr)
+	flags.Usage = func() {
+		_, _ = fmt.Fprintf(env.stderr, "Usage: gemara %s [flags] %s\n\n%s\n\nFlags:\n", name, arguments, description)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// parseFlags parses the command flags and checks the number of positional arguments.
+func parseFlags(flags *flag.FlagSet, args []string, minArgs, maxArgs int) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	if flags.NArg() < minArgs || (maxArgs >= 0 && flags.NArg() > maxArgs) {
+		flags.Usage()
+		return errUsage
+	}
+	return nil
+}
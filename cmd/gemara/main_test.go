@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ossf/gemara/layer1"
+	"github.com/ossf/gemara/layer4"
+)
+
+const (
+	goodCatalog    = "../../layer2/test-data/good-ccc.yaml"
+	badCatalog     = "../../layer2/test-data/bad.yaml"
+	evaluationRuns = "../../layer4/test-data/pvtr-baseline-scan.yaml"
+)
+
+func runCommand(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := run(args, environment{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr})
+	return code, stdout.String(), stderr.String()
+}
+
+func writeTemp(t *testing.T, name string, value interface{}) string {
+	t.Helper()
+	data, err := yaml.Marshal(value)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func testGuidance() layer1.GuidanceDocument {
+	return layer1.GuidanceDocument{
+		Metadata: layer1.Metadata{Id: "EXP", Title: "Example Guidance", Description: "Example", Author: "Example Author", Version: "1.0.0"},
+		Categories: []layer1.Category{
+			{
+				Id:          "DET",
+				Title:       "Detective",
+				Description: "Detection",
+				Guidelines:  []layer1.Guideline{{Id: "EXP-DET-1", Title: "Feedback", Objective: "Collect feedback."}},
+			},
+		},
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	code, _, stderr := runCommand(t, "")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, "Usage: gemara <command>")
+
+	code, _, stderr = runCommand(t, "", "bogus")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, stderr, `unknown command "bogus"`)
+
+	code, _, _ = runCommand(t, "", "validate")
+	assert.Equal(t, 2, code, "validate requires a file")
+}
+
+func TestValidate(t *testing.T) {
+	code, stdout, _ := runCommand(t, "", "validate", goodCatalog, evaluationRuns, writeTemp(t, "guidance.yaml", testGuidance()))
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stdout, "good-ccc.yaml: structurally valid Layer 2 document")
+	assert.Contains(t, stdout, "pvtr-baseline-scan.yaml: structurally valid Layer 4 document")
+	assert.Contains(t, stdout, "guidance.yaml: structurally valid Layer 1 document")
+
+	code, _, stderr := runCommand(t, "", "validate", badCatalog)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "unable to detect the document layer")
+
+	code, _, stderr = runCommand(t, "", "validate", "-layer", "2", badCatalog)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `document: unknown field "this"`)
+
+	code, _, stderr = runCommand(t, "control-families:\n  - id: fam\n    title: Family\n", "validate", "-")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, `control-families[0]: missing required field "description"`)
+
+	code, _, stderr = runCommand(t, "", "validate", "-h")
+	assert.Equal(t, 0, code)
+	assert.Contains(t, stderr, "Other constraints of the CUE schemas")
+}
+
+func TestValidateAttestedResults(t *testing.T) {
+	results := &layer4.EvaluationResults{}
+	require.NoError(t, results.LoadFile(evaluationRuns))
+	var attestations []layer4.Attestation
+	for _, evaluation := range results.EvaluationSet {
+		for _, assessment := range evaluation.Assessments {
+			if assessment.Result == layer4.NeedsReview {
+				attestations = append(attestations, layer4.Attestation{
+					ControlId: evaluation.ControlID, RequirementId: assessment.RequirementId,
+					Attester: "alice", AttestedOn: "2025-05-01T00:00:00Z", Decision: layer4.Passed,
+				})
+			}
+		}
+	}
+	report, err := results.ApplyAttestations(attestations)
+	require.NoError(t, err)
+	require.NotEmpty(t, report.Applied)
+
+	code, stdout, stderr := runCommand(t, "", "validate", writeTemp(t, "attested.yaml", results))
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "attested.yaml: structurally valid Layer 4 document")
+}
+
+func requiresMFA(payload interface{}, _ map[string]*layer4.Change) (layer4.Result, string) {
+	return layer4.Passed, "MFA is required"
+}
+
+func TestValidateRegisteredSteps(t *testing.T) {
+	require.NoError(t, layer4.RegisterStep(requiresMFA, layer4.StepInfo{Id: "requires-mfa", Description: "Checks that MFA is required", Version: "1.0.0"}))
+	evaluation := &layer4.ControlEvaluation{Name: "MFA", ControlID: "OSPS-AC-01"}
+	evaluation.AddAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []layer4.AssessmentStep{requiresMFA})
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, false)
+	results := &layer4.EvaluationResults{EvaluationSet: []*layer4.ControlEvaluation{evaluation}}
+
+	path := writeTemp(t, "registered.yaml", results)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "step-details")
+	code, stdout, stderr := runCommand(t, "", "validate", path)
+	assert.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "registered.yaml: structurally valid Layer 4 document")
+}
+
+func TestOSCAL(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "", "oscal", "-control-href", "https://example.com/%s/%s", goodCatalog)
+	require.Equal(t, 0, code, stderr)
+	var models map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &models))
+	assert.Contains(t, models, "catalog")
+
+	guidance := writeTemp(t, "guidance.yaml", testGuidance())
+	code, stdout, stderr = runCommand(t, "", "oscal", "-type", "profile", "-catalog-href", "catalog.json", "-format", "yaml", guidance)
+	require.Equal(t, 0, code, stderr)
+	assert.True(t, strings.HasPrefix(stdout, "profile:"))
+
+	code, _, stderr = runCommand(t, "", "oscal", goodCatalog)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "-control-href is required")
+
+	code, _, stderr = runCommand(t, "", "oscal", evaluationRuns)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "cannot be converted to OSCAL")
+}
+
+func TestRender(t *testing.T) {
+	catalog, err := os.ReadFile(goodCatalog)
+	require.NoError(t, err)
+
+	code, stdout, stderr := runCommand(t, string(catalog), "render", "-")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "# FINOS Cloud Control Catalog")
+
+	code, stdout, stderr = runCommand(t, "", "render", "-format", "html", evaluationRuns)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "<!DOCTYPE html>")
+}
+
+func TestDiff(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "", "diff", goodCatalog, goodCatalog)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "No changes.")
+
+	original := testGuidance()
+	updated := testGuidance()
+	updated.Categories[0].Guidelines[0].Objective = "Collect and review feedback."
+	code, stdout, stderr = runCommand(t, "", "diff", "-format", "json",
+		writeTemp(t, "old.yaml", original), writeTemp(t, "new.yaml", updated))
+	require.Equal(t, 0, code, stderr)
+	var diff layer1.GuidanceDiff
+	require.NoError(t, json.Unmarshal([]byte(stdout), &diff))
+	require.Len(t, diff.Guidelines, 1)
+	assert.Equal(t, layer1.DiffModified, diff.Guidelines[0].Type)
+
+	results := &layer4.EvaluationResults{}
+	require.NoError(t, results.LoadFile(evaluationRuns))
+	results.EvaluationSet[0].Assessments[0].Result = layer4.Failed
+	failing := writeTemp(t, "failing.yaml", results)
+
+	code, stdout, stderr = runCommand(t, "", "diff", "-fail-on-regression", evaluationRuns, failing)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stdout, "## Newly Failing")
+	assert.Contains(t, stderr, "1 newly failing")
+}
+
+func TestSummary(t *testing.T) {
+	code, stdout, stderr := runCommand(t, "", "summary", "-format", "json", evaluationRuns)
+	require.Equal(t, 0, code, stderr)
+	var summary layer4.Summary
+	require.NoError(t, json.Unmarshal([]byte(stdout), &summary))
+	assert.Equal(t, 54, summary.Counts.Total())
+
+	code, stdout, stderr = runCommand(t, "", "summary", "-no-color", evaluationRuns)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "54 assessments: 13 passed, 4 failed")
+	assert.NotContains(t, stdout, "\x1b[")
+}
+
+func TestSignAndVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	dir := t.TempDir()
+	privateKey := filepath.Join(dir, "key.pem")
+	publicKey := filepath.Join(dir, "key.pub")
+	require.NoError(t, os.WriteFile(privateKey, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+	require.NoError(t, os.WriteFile(publicKey, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600))
+
+	code, envelope, stderr := runCommand(t, "", "sign", "-key", privateKey, "-catalog", goodCatalog, evaluationRuns)
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, envelope, `"payloadType": "application/vnd.in-toto+json"`)
+
+	output := filepath.Join(dir, "verified.yaml")
+	code, stdout, stderr := runCommand(t, envelope, "verify", "-key", publicKey, "-output", output, "-")
+	require.Equal(t, 0, code, stderr)
+	assert.Contains(t, stdout, "-: verified evaluation results for catalog")
+	original, verified := &layer4.EvaluationResults{}, &layer4.EvaluationResults{}
+	require.NoError(t, original.LoadFile(evaluationRuns))
+	require.NoError(t, verified.LoadFile(output))
+	assert.Len(t, verified.EvaluationSet, len(original.EvaluationSet))
+
+	code, _, stderr = runCommand(t, strings.Replace(envelope, `"sig": "`, `"sig": "AA`, 1), "verify", "-key", publicKey, "-")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, stderr, "no valid signature")
+}
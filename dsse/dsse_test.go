@@ -0,0 +1,73 @@
+package dsse
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+)
+
+func TestPAE(t *testing.T) {
+	got := string(PAE("http://example.com/HelloWorld", []byte("hello world")))
+	want := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
+
+func TestSignAndVerify(t *testing.T) {
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	for name, signer := range map[string]crypto.Signer{"ed25519": edKey, "ecdsa": ecKey, "rsa": rsaKey} {
+		t.Run(name, func(t *testing.T) {
+			envelope, err := Sign(InTotoPayloadType, []byte(`{"hello":"world"}`), signer, "test-key")
+			if err != nil {
+				t.Fatal(err)
+			}
+			payload, err := envelope.Verify(otherKey.Public(), signer.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(payload) != `{"hello":"world"}` {
+				t.Errorf("unexpected payload %s", payload)
+			}
+			if _, err := envelope.Verify(otherKey.Public()); err == nil {
+				t.Error("expected verification with another key to fail")
+			}
+
+			envelope.PayloadType = "application/json"
+			if _, err := envelope.Verify(signer.Public()); err == nil {
+				t.Error("expected verification of a modified envelope to fail")
+			}
+		})
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	privateDER, _ := x509.MarshalPKCS8PrivateKey(private)
+	publicDER, _ := x509.MarshalPKIXPublicKey(public)
+
+	signer, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !public.Equal(signer.Public()) || !public.Equal(key) {
+		t.Error("expected the parsed keys to match")
+	}
+	if _, err := ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Error("expected an error for invalid PEM data")
+	}
+}
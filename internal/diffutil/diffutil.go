@@ -0,0 +1,44 @@
+// Package diffutil provides the text and key comparisons shared by the Layer 1 and Layer 2 diffs.
+package diffutil
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// NormalizeText collapses all whitespace, such as that introduced by reformatting YAML block scalars,
+// into single spaces.
+func NormalizeText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// Difference returns the values in a that are not in b, ignoring whitespace differences.
+func Difference(a, b []string) []string {
+	normalized := make([]string, 0, len(b))
+	for _, value := range b {
+		normalized = append(normalized, NormalizeText(value))
+	}
+	var values []string
+	for _, value := range a {
+		if !slices.Contains(normalized, NormalizeText(value)) {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// UnionKeys returns the sorted keys of both maps.
+func UnionKeys[T any](a, b map[string]T) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, found := a[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
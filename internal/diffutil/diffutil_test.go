@@ -0,0 +1,22 @@
+package diffutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDifference(t *testing.T) {
+	old := []string{"Maturity Level 1", "tlp_green"}
+	updated := []string{"Maturity  Level 1\n", "tlp_clear"}
+
+	assert.Equal(t, []string{"tlp_clear"}, Difference(updated, old))
+	assert.Equal(t, []string{"tlp_green"}, Difference(old, updated))
+}
+
+func TestUnionKeys(t *testing.T) {
+	a := map[string]int{"b": 1, "a": 2}
+	b := map[string]int{"c": 3, "a": 4}
+
+	assert.Equal(t, []string{"a", "b", "c"}, UnionKeys(a, b))
+}
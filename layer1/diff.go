@@ -0,0 +1,389 @@
+package layer1
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/ossf/gemara/internal/diffutil"
+)
+
+// DiffType describes how an entry changed between two versions of a guidance document.
+type DiffType string
+
+const (
+	DiffAdded    DiffType = "added"
+	DiffRemoved  DiffType = "removed"
+	DiffModified DiffType = "modified"
+	// DiffRenumbered is used for enhancements that were assigned a new ID and kept their title.
+	// Any other changes to a renumbered enhancement are listed as for a modified one.
+	DiffRenumbered DiffType = "renumbered"
+)
+
+// GuidanceDiff contains the differences between two versions of a GuidanceDocument.
+// Entries are matched by their ID rather than by their position in the document.
+type GuidanceDiff struct {
+	// DocumentId is the ID of the updated guidance document
+	DocumentId string `json:"document-id" yaml:"document-id"`
+	// OldVersion is the version of the original guidance document
+	OldVersion string `json:"old-version,omitempty" yaml:"old-version,omitempty"`
+	// NewVersion is the version of the updated guidance document
+	NewVersion string `json:"new-version,omitempty" yaml:"new-version,omitempty"`
+	// Categories lists the categories that were added, removed or modified
+	Categories []EntryDiff `json:"categories,omitempty" yaml:"categories,omitempty"`
+	// Guidelines lists the guidelines that were added, removed, modified or renumbered
+	Guidelines []GuidelineDiff `json:"guidelines,omitempty" yaml:"guidelines,omitempty"`
+	// Principles lists the principles that were added, removed or modified
+	Principles []EntryDiff `json:"principles,omitempty" yaml:"principles,omitempty"`
+}
+
+// GuidelineDiff describes the changes to a single guideline.
+type GuidelineDiff struct {
+	// GuidelineId is the unique identifier of the guideline in the updated document,
+	// or in the original document if the guideline was removed
+	GuidelineId string `json:"guideline-id" yaml:"guideline-id"`
+	// PreviousId is the unique identifier of a renumbered guideline in the original document
+	PreviousId string `json:"previous-id,omitempty" yaml:"previous-id,omitempty"`
+	// Category is the ID of the category containing the guideline
+	Category string `json:"category,omitempty" yaml:"category,omitempty"`
+	// Type describes whether the guideline was added, removed, modified or renumbered
+	Type DiffType `json:"type" yaml:"type"`
+	// Fields lists the changed fields of a modified or renumbered guideline
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// RecommendationsAdded lists the recommendations that were added to the guideline
+	RecommendationsAdded []string `json:"recommendations-added,omitempty" yaml:"recommendations-added,omitempty"`
+	// RecommendationsRemoved lists the recommendations that were removed from the guideline
+	RecommendationsRemoved []string `json:"recommendations-removed,omitempty" yaml:"recommendations-removed,omitempty"`
+	// Parts lists the guideline parts that were added, removed or modified
+	Parts []PartDiff `json:"parts,omitempty" yaml:"parts,omitempty"`
+	// GuidelineMappings lists the guideline mapping entries that were added, removed or modified
+	GuidelineMappings []MappingDiff `json:"guideline-mappings,omitempty" yaml:"guideline-mappings,omitempty"`
+	// PrincipleMappings lists the principle mapping entries that were added, removed or modified
+	PrincipleMappings []MappingDiff `json:"principle-mappings,omitempty" yaml:"principle-mappings,omitempty"`
+}
+
+// PartDiff describes the changes to a single guideline part.
+type PartDiff struct {
+	// PartId is the unique identifier of the part
+	PartId string `json:"part-id" yaml:"part-id"`
+	// Type describes whether the part was added, removed or modified
+	Type DiffType `json:"type" yaml:"type"`
+	// Fields lists the changed fields of a modified part
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// RecommendationsAdded lists the recommendations that were added to the part
+	RecommendationsAdded []string `json:"recommendations-added,omitempty" yaml:"recommendations-added,omitempty"`
+	// RecommendationsRemoved lists the recommendations that were removed from the part
+	RecommendationsRemoved []string `json:"recommendations-removed,omitempty" yaml:"recommendations-removed,omitempty"`
+}
+
+// MappingDiff describes the changes to a single mapping entry.
+type MappingDiff struct {
+	// ReferenceId is the ID of the mapping reference containing the entry
+	ReferenceId string `json:"reference-id" yaml:"reference-id"`
+	// EntryId is the ID of the mapped entry
+	EntryId string `json:"entry-id" yaml:"entry-id"`
+	// Type describes whether the mapping entry was added, removed or modified
+	Type DiffType `json:"type" yaml:"type"`
+	// Fields lists the changed fields of a modified mapping entry
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// EntryDiff describes the changes to a category or principle.
+type EntryDiff struct {
+	// Id is the unique identifier of the entry
+	Id string `json:"id" yaml:"id"`
+	// Type describes whether the entry was added, removed or modified
+	Type DiffType `json:"type" yaml:"type"`
+	// Fields lists the changed fields of a modified entry
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// FieldChange records the previous and updated value of a single field.
+type FieldChange struct {
+	Field string `json:"field" yaml:"field"`
+	Old   string `json:"old" yaml:"old"`
+	New   string `json:"new" yaml:"new"`
+}
+
+// IsEmpty returns true if no differences were found.
+func (d GuidanceDiff) IsEmpty() bool {
+	return len(d.Categories) == 0 && len(d.Guidelines) == 0 && len(d.Principles) == 0
+}
+
+// GetGuideline returns the diff for the guideline with the given ID in the original document.
+// Renumbered guidelines are found by their previous ID.
+func (d GuidanceDiff) GetGuideline(guidelineId string) (GuidelineDiff, bool) {
+	for _, guideline := range d.Guidelines {
+		if guideline.Type == DiffAdded {
+			continue
+		}
+		if guideline.PreviousId == guidelineId || (guideline.PreviousId == "" && guideline.GuidelineId == guidelineId) {
+			return guideline, true
+		}
+	}
+	return GuidelineDiff{}, false
+}
+
+// Diff compares the guidance document with an updated version of it and returns the differences.
+// Categories, guidelines, parts, principles and mapping entries are matched by ID. Enhancements
+// that were removed and added again under a new ID with the same title are reported as renumbered,
+// along with any other changes to them.
+func (g *GuidanceDocument) Diff(updated *GuidanceDocument) GuidanceDiff {
+	diff := GuidanceDiff{
+		DocumentId: updated.Metadata.Id,
+		OldVersion: g.Metadata.Version,
+		NewVersion: updated.Metadata.Version,
+	}
+
+	oldCategories := make(map[string]Category)
+	for _, category := range g.Categories {
+		oldCategories[category.Id] = category
+	}
+	newCategories := make(map[string]Category)
+	for _, category := range updated.Categories {
+		newCategories[category.Id] = category
+	}
+	for _, id := range diffutil.UnionKeys(oldCategories, newCategories) {
+		oldCategory, inOld := oldCategories[id]
+		newCategory, inNew := newCategories[id]
+		switch {
+		case !inOld:
+			diff.Categories = append(diff.Categories, EntryDiff{Id: id, Type: DiffAdded})
+		case !inNew:
+			diff.Categories = append(diff.Categories, EntryDiff{Id: id, Type: DiffRemoved})
+		default:
+			fields := DiffFields(
+				FieldChange{"title", oldCategory.Title, newCategory.Title},
+				FieldChange{"description", oldCategory.Description, newCategory.Description},
+			)
+			if len(fields) > 0 {
+				diff.Categories = append(diff.Categories, EntryDiff{Id: id, Type: DiffModified, Fields: fields})
+			}
+		}
+	}
+
+	oldGuidelines := indexGuidelines(g)
+	newGuidelines := indexGuidelines(updated)
+	var added, removed []GuidelineDiff
+	for _, id := range diffutil.UnionKeys(oldGuidelines, newGuidelines) {
+		oldGuideline, inOld := oldGuidelines[id]
+		newGuideline, inNew := newGuidelines[id]
+		switch {
+		case !inOld:
+			added = append(added, GuidelineDiff{GuidelineId: id, Category: newGuideline.category, Type: DiffAdded})
+		case !inNew:
+			removed = append(removed, GuidelineDiff{GuidelineId: id, Category: oldGuideline.category, Type: DiffRemoved})
+		default:
+			if guidelineDiff, changed := diffGuideline(oldGuideline, newGuideline); changed {
+				diff.Guidelines = append(diff.Guidelines, guidelineDiff)
+			}
+		}
+	}
+	diff.Guidelines = append(diff.Guidelines, matchRenumbered(added, removed, oldGuidelines, newGuidelines)...)
+	sort.SliceStable(diff.Guidelines, func(i, j int) bool {
+		return diff.Guidelines[i].GuidelineId < diff.Guidelines[j].GuidelineId
+	})
+
+	oldPrinciples := make(map[string]Principle)
+	for _, principle := range g.Principles {
+		oldPrinciples[principle.Id] = principle
+	}
+	newPrinciples := make(map[string]Principle)
+	for _, principle := range updated.Principles {
+		newPrinciples[principle.Id] = principle
+	}
+	for _, id := range diffutil.UnionKeys(oldPrinciples, newPrinciples) {
+		oldPrinciple, inOld := oldPrinciples[id]
+		newPrinciple, inNew := newPrinciples[id]
+		switch {
+		case !inOld:
+			diff.Principles = append(diff.Principles, EntryDiff{Id: id, Type: DiffAdded})
+		case !inNew:
+			diff.Principles = append(diff.Principles, EntryDiff{Id: id, Type: DiffRemoved})
+		default:
+			fields := DiffFields(
+				FieldChange{"title", oldPrinciple.Title, newPrinciple.Title},
+				FieldChange{"description", oldPrinciple.Description, newPrinciple.Description},
+			)
+			if len(fields) > 0 {
+				diff.Principles = append(diff.Principles, EntryDiff{Id: id, Type: DiffModified, Fields: fields})
+			}
+		}
+	}
+
+	return diff
+}
+
+type categoryGuideline struct {
+	Guideline
+	category string
+}
+
+func indexGuidelines(doc *GuidanceDocument) map[string]categoryGuideline {
+	guidelines := make(map[string]categoryGuideline)
+	for _, category := range doc.Categories {
+		for _, guideline := range category.Guidelines {
+			guidelines[guideline.Id] = categoryGuideline{Guideline: guideline, category: category.Id}
+		}
+	}
+	return guidelines
+}
+
+// matchRenumbered pairs removed and added enhancements with the same title, reporting them as
+// renumbered. Any remaining additions and removals are returned unchanged.
+func matchRenumbered(added, removed []GuidelineDiff, oldGuidelines, newGuidelines map[string]categoryGuideline) []GuidelineDiff {
+	var diffs []GuidelineDiff
+	matched := make(map[string]bool)
+	for _, removedDiff := range removed {
+		oldGuideline := oldGuidelines[removedDiff.GuidelineId]
+		renumbered := false
+		for _, addedDiff := range added {
+			newGuideline := newGuidelines[addedDiff.GuidelineId]
+			if matched[addedDiff.GuidelineId] || oldGuideline.BaseGuidelineID == "" || newGuideline.BaseGuidelineID == "" {
+				continue
+			}
+			if diffutil.NormalizeText(oldGuideline.Title) != diffutil.NormalizeText(newGuideline.Title) {
+				continue
+			}
+			guidelineDiff, _ := diffGuideline(oldGuideline, newGuideline)
+			guidelineDiff.Type = DiffRenumbered
+			guidelineDiff.PreviousId = oldGuideline.Id
+			diffs = append(diffs, guidelineDiff)
+			matched[addedDiff.GuidelineId] = true
+			renumbered = true
+			break
+		}
+		if !renumbered {
+			diffs = append(diffs, removedDiff)
+		}
+	}
+	for _, addedDiff := range added {
+		if !matched[addedDiff.GuidelineId] {
+			diffs = append(diffs, addedDiff)
+		}
+	}
+	return diffs
+}
+
+func diffGuideline(oldGuideline, newGuideline categoryGuideline) (GuidelineDiff, bool) {
+	diff := GuidelineDiff{
+		GuidelineId: newGuideline.Id,
+		Category:    newGuideline.category,
+		Type:        DiffModified,
+		Fields: DiffFields(
+			FieldChange{"title", oldGuideline.Title, newGuideline.Title},
+			FieldChange{"objective", oldGuideline.Objective, newGuideline.Objective},
+			FieldChange{"base-guideline-id", oldGuideline.BaseGuidelineID, newGuideline.BaseGuidelineID},
+			FieldChange{"category", oldGuideline.category, newGuideline.category},
+		),
+		RecommendationsAdded:   diffutil.Difference(newGuideline.Recommendations, oldGuideline.Recommendations),
+		RecommendationsRemoved: diffutil.Difference(oldGuideline.Recommendations, newGuideline.Recommendations),
+	}
+
+	oldParts := make(map[string]Part)
+	for _, part := range oldGuideline.GuidelineParts {
+		oldParts[part.Id] = part
+	}
+	newParts := make(map[string]Part)
+	for _, part := range newGuideline.GuidelineParts {
+		newParts[part.Id] = part
+	}
+	for _, id := range diffutil.UnionKeys(oldParts, newParts) {
+		oldPart, inOld := oldParts[id]
+		newPart, inNew := newParts[id]
+		switch {
+		case !inOld:
+			diff.Parts = append(diff.Parts, PartDiff{PartId: id, Type: DiffAdded})
+		case !inNew:
+			diff.Parts = append(diff.Parts, PartDiff{PartId: id, Type: DiffRemoved})
+		default:
+			partDiff := PartDiff{
+				PartId: id,
+				Type:   DiffModified,
+				Fields: DiffFields(
+					FieldChange{"title", oldPart.Title, newPart.Title},
+					FieldChange{"prose", oldPart.Prose, newPart.Prose},
+				),
+				RecommendationsAdded:   diffutil.Difference(newPart.Recommendations, oldPart.Recommendations),
+				RecommendationsRemoved: diffutil.Difference(oldPart.Recommendations, newPart.Recommendations),
+			}
+			if len(partDiff.Fields) > 0 || len(partDiff.RecommendationsAdded) > 0 || len(partDiff.RecommendationsRemoved) > 0 {
+				diff.Parts = append(diff.Parts, partDiff)
+			}
+		}
+	}
+
+	diff.GuidelineMappings = DiffMappings(oldGuideline.GuidelineMappings, newGuideline.GuidelineMappings)
+	diff.PrincipleMappings = DiffMappings(oldGuideline.PrincipleMappings, newGuideline.PrincipleMappings)
+
+	changed := len(diff.Fields) > 0 || len(diff.RecommendationsAdded) > 0 || len(diff.RecommendationsRemoved) > 0 ||
+		len(diff.Parts) > 0 || len(diff.GuidelineMappings) > 0 || len(diff.PrincipleMappings) > 0
+	return diff, changed
+}
+
+type mappingKey struct {
+	referenceId string
+	entryId     string
+}
+
+// DiffMappings compares two sets of mappings and returns the entries that were added, removed or modified,
+// matched by reference ID and entry ID and sorted by them.
+func DiffMappings(oldMappings, newMappings []Mapping) []MappingDiff {
+	index := func(mappings []Mapping) (map[mappingKey]MappingEntry, []mappingKey) {
+		entries := make(map[mappingKey]MappingEntry)
+		var keys []mappingKey
+		for _, mapping := range mappings {
+			for _, entry := range mapping.Entries {
+				key := mappingKey{mapping.ReferenceId, entry.ReferenceId}
+				if _, found := entries[key]; !found {
+					keys = append(keys, key)
+				}
+				entries[key] = entry
+			}
+		}
+		return entries, keys
+	}
+	oldEntries, oldKeys := index(oldMappings)
+	newEntries, newKeys := index(newMappings)
+
+	var diffs []MappingDiff
+	for _, key := range oldKeys {
+		oldEntry := oldEntries[key]
+		newEntry, found := newEntries[key]
+		if !found {
+			diffs = append(diffs, MappingDiff{ReferenceId: key.referenceId, EntryId: key.entryId, Type: DiffRemoved})
+			continue
+		}
+		fields := DiffFields(
+			FieldChange{"strength", strconv.FormatInt(oldEntry.Strength, 10), strconv.FormatInt(newEntry.Strength, 10)},
+			FieldChange{"remarks", oldEntry.Remarks, newEntry.Remarks},
+		)
+		if len(fields) > 0 {
+			diffs = append(diffs, MappingDiff{ReferenceId: key.referenceId, EntryId: key.entryId, Type: DiffModified, Fields: fields})
+		}
+	}
+	for _, key := range newKeys {
+		if _, found := oldEntries[key]; !found {
+			diffs = append(diffs, MappingDiff{ReferenceId: key.referenceId, EntryId: key.entryId, Type: DiffAdded})
+		}
+	}
+
+	sort.SliceStable(diffs, func(i, j int) bool {
+		if diffs[i].ReferenceId != diffs[j].ReferenceId {
+			return diffs[i].ReferenceId < diffs[j].ReferenceId
+		}
+		return diffs[i].EntryId < diffs[j].EntryId
+	})
+	return diffs
+}
+
+// DiffFields returns the fields whose values differ. Whitespace differences, such as
+// those introduced by reformatting YAML block scalars, are not considered changes.
+func DiffFields(fields ...FieldChange) []FieldChange {
+	var changed []FieldChange
+	for _, field := range fields {
+		if diffutil.NormalizeText(field.Old) != diffutil.NormalizeText(field.New) {
+			changed = append(changed, field)
+		}
+	}
+	return changed
+}
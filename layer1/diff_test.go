@@ -0,0 +1,162 @@
+package layer1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withEnhancement(g *GuidanceDocument, id, title string) {
+	g.Categories[0].Guidelines = append(g.Categories[0].Guidelines, Guideline{
+		Id:              id,
+		Title:           title,
+		Objective:       "Review collected feedback on a regular basis.",
+		BaseGuidelineID: "AIR-DET-011",
+	})
+}
+
+func lastGuideline(g *GuidanceDocument) *Guideline {
+	guidelines := g.Categories[0].Guidelines
+	return &guidelines[len(guidelines)-1]
+}
+
+func TestGuidanceDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(g *GuidanceDocument)
+		want   func(t *testing.T, diff GuidanceDiff)
+	}{
+		{
+			name:   "No changes",
+			update: func(g *GuidanceDocument) {},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				assert.True(t, diff.IsEmpty())
+			},
+		},
+		{
+			name: "Whitespace changes are ignored",
+			update: func(g *GuidanceDocument) {
+				g.Categories[0].Guidelines[0].Objective += "\n"
+			},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				assert.True(t, diff.IsEmpty())
+			},
+		},
+		{
+			name: "Category modified and added",
+			update: func(g *GuidanceDocument) {
+				g.Categories[0].Description = "Detection"
+				g.Categories = append(g.Categories, Category{Id: "PREV", Title: "Preventive"})
+			},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				assert.Equal(t, []EntryDiff{
+					{Id: "DET", Type: DiffModified, Fields: []FieldChange{{Field: "description", Old: "Detection and Continuous Improvement", New: "Detection"}}},
+					{Id: "PREV", Type: DiffAdded},
+				}, diff.Categories)
+				assert.Empty(t, diff.Guidelines)
+			},
+		},
+		{
+			name: "Parts and recommendations changed",
+			update: func(g *GuidanceDocument) {
+				guideline := &g.Categories[0].Guidelines[0]
+				guideline.Recommendations = []string{"Review feedback weekly"}
+				guideline.GuidelineParts[0].Prose = "Design the mechanism carefully."
+				guideline.GuidelineParts = guideline.GuidelineParts[:1]
+			},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				require.Len(t, diff.Guidelines, 1)
+				guideline := diff.Guidelines[0]
+				assert.Equal(t, "AIR-DET-011", guideline.GuidelineId)
+				assert.Equal(t, DiffModified, guideline.Type)
+				assert.Equal(t, []string{"Review feedback weekly"}, guideline.RecommendationsAdded)
+				require.Len(t, guideline.Parts, 2)
+				assert.Equal(t, "AIR-DET-011.1", guideline.Parts[0].PartId)
+				assert.Equal(t, DiffModified, guideline.Parts[0].Type)
+				assert.Equal(t, "prose", guideline.Parts[0].Fields[0].Field)
+				assert.Equal(t, PartDiff{PartId: "AIR-DET-011.2", Type: DiffRemoved}, guideline.Parts[1])
+			},
+		},
+		{
+			name: "Mappings changed",
+			update: func(g *GuidanceDocument) {
+				mapping := &g.Categories[0].Guidelines[0].GuidelineMappings[0]
+				mapping.Entries = mapping.Entries[1:]
+				mapping.Entries[0].Strength = 9
+			},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				require.Len(t, diff.Guidelines, 1)
+				assert.Equal(t, []MappingDiff{
+					{ReferenceId: "NIST-800-53", EntryId: "CA-7", Type: DiffRemoved},
+					{ReferenceId: "NIST-800-53", EntryId: "IR-6", Type: DiffModified, Fields: []FieldChange{{Field: "strength", Old: "5", New: "9"}}},
+				}, diff.Guidelines[0].GuidelineMappings)
+			},
+		},
+		{
+			name: "Enhancement renumbered",
+			update: func(g *GuidanceDocument) {
+				enhancement := lastGuideline(g)
+				enhancement.Id = "AIR-DET-011(2)"
+			},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				require.Len(t, diff.Guidelines, 1)
+				assert.Equal(t, GuidelineDiff{
+					GuidelineId: "AIR-DET-011(2)",
+					PreviousId:  "AIR-DET-011(1)",
+					Category:    "DET",
+					Type:        DiffRenumbered,
+				}, diff.Guidelines[0])
+
+				renumbered, found := diff.GetGuideline("AIR-DET-011(1)")
+				assert.True(t, found)
+				assert.Equal(t, DiffRenumbered, renumbered.Type)
+			},
+		},
+		{
+			name: "Enhancement renumbered with changes",
+			update: func(g *GuidanceDocument) {
+				enhancement := lastGuideline(g)
+				enhancement.Id = "AIR-DET-011(2)"
+				enhancement.Objective = "Review feedback weekly."
+			},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				require.Len(t, diff.Guidelines, 1)
+				assert.Equal(t, DiffRenumbered, diff.Guidelines[0].Type)
+				assert.Equal(t, "AIR-DET-011(1)", diff.Guidelines[0].PreviousId)
+				require.Len(t, diff.Guidelines[0].Fields, 1)
+				assert.Equal(t, "objective", diff.Guidelines[0].Fields[0].Field)
+			},
+		},
+		{
+			name: "Enhancement replaced",
+			update: func(g *GuidanceDocument) {
+				enhancement := lastGuideline(g)
+				enhancement.Id = "AIR-DET-011(2)"
+				enhancement.Title = "Feedback Retention"
+			},
+			want: func(t *testing.T, diff GuidanceDiff) {
+				require.Len(t, diff.Guidelines, 2)
+				assert.Equal(t, GuidelineDiff{GuidelineId: "AIR-DET-011(1)", Category: "DET", Type: DiffRemoved}, diff.Guidelines[0])
+				assert.Equal(t, GuidelineDiff{GuidelineId: "AIR-DET-011(2)", Category: "DET", Type: DiffAdded}, diff.Guidelines[1])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := goodAIGFExample()
+			withEnhancement(&original, "AIR-DET-011(1)", "Feedback Review")
+			updated := goodAIGFExample()
+			updated.Metadata.Version = "0.2.0"
+			withEnhancement(&updated, "AIR-DET-011(1)", "Feedback Review")
+			tt.update(&updated)
+
+			diff := original.Diff(&updated)
+			assert.Equal(t, "FINOS-AIR", diff.DocumentId)
+			assert.Equal(t, "0.1.0", diff.OldVersion)
+			assert.Equal(t, "0.2.0", diff.NewVersion)
+			tt.want(t, diff)
+		})
+	}
+}
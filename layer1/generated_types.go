@@ -10,6 +10,9 @@ type GuidanceDocument struct {
 
 	Categories	[]Category	`json:"categories,omitempty" yaml:"categories,omitempty"`
 
+	// Foundational tenets that guidelines in this or other documents map to
+	Principles	[]Principle	`json:"principles,omitempty" yaml:"principles,omitempty"`
+
 	// For inheriting from other guidance documents to create tailored documents/baselines
 	ImportedGuidelines	[]Mapping	`json:"imported-guidelines,omitempty" yaml:"imported-guidelines,omitempty"`
 
@@ -177,3 +180,17 @@ type MappingEntry struct {
 
 	Remarks	string	`json:"remarks,omitempty" yaml:"remarks,omitempty"`
 }
+
+// Principle represents a foundational tenet or value that guidelines are intended to uphold
+type Principle struct {
+	Id	string	`json:"id" yaml:"id"`
+
+	Title	string	`json:"title" yaml:"title"`
+
+	Description	string	`json:"description" yaml:"description"`
+
+	Rationale	*Rationale	`json:"rationale,omitempty" yaml:"rationale,omitempty"`
+
+	// Corresponds to the resource ids in metadata to map to external unstructured resources
+	ExternalReferences	[]string	`json:"external-references,omitempty" yaml:"external-references,omitempty"`
+}
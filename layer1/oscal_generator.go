@@ -117,11 +117,12 @@ func (g *GuidanceDocument) ToOSCALProfile(guidanceDocHref string, opts ...Genera
 }
 
 // ToOSCALCatalog creates an OSCAL Catalog from the locally defined guidelines in a given
-// Layer 1 Guidance Document.
+// Layer 1 Guidance Document. Locally defined principles are rendered as a separate group
+// of controls with the "principle" class.
 func (g *GuidanceDocument) ToOSCALCatalog(opts ...GenerateOption) (oscal.Catalog, error) {
 	// Return early for empty documents
-	if len(g.Categories) == 0 {
-		return oscal.Catalog{}, fmt.Errorf("document %s does not have defined guidance categories", g.Metadata.Id)
+	if len(g.Categories) == 0 && len(g.Principles) == 0 {
+		return oscal.Catalog{}, fmt.Errorf("document %s does not have defined guidance categories or principles", g.Metadata.Id)
 	}
 
 	options := generateOpts{}
@@ -151,6 +152,9 @@ func (g *GuidanceDocument) ToOSCALCatalog(opts ...GenerateOption) (oscal.Catalog
 	for _, category := range g.Categories {
 		groups = append(groups, g.createControlGroup(category, resourcesMap))
 	}
+	if len(g.Principles) > 0 {
+		groups = append(groups, g.createPrincipleGroup(resourcesMap))
+	}
 
 	catalog := oscal.Catalog{
 		UUID:       uuid.NewUUID(),
@@ -235,6 +239,36 @@ func (g *GuidanceDocument) createControlGroup(category Category, resourcesMap ma
 	return group
 }
 
+func (g *GuidanceDocument) createPrincipleGroup(resourcesMap map[string]string) oscal.Group {
+	group := oscal.Group{
+		Class: "principles",
+		ID:    "principles",
+		Title: "Principles",
+	}
+
+	controls := make([]oscal.Control, 0, len(g.Principles))
+	for _, principle := range g.Principles {
+		controlId := oscalUtils.NormalizeControl(principle.Id, false)
+		control := oscal.Control{
+			ID:    controlId,
+			Title: principle.Title,
+			Class: "principle",
+			Parts: &[]oscal.Part{
+				{
+					Name:  "statement",
+					ID:    fmt.Sprintf("%s_smt", controlId),
+					Prose: principle.Description,
+				},
+			},
+		}
+		control.Links = oscalUtils.NilIfEmpty(externalLinks(principle.ExternalReferences, resourcesMap))
+		controls = append(controls, control)
+	}
+
+	group.Controls = oscalUtils.NilIfEmpty(controls)
+	return group
+}
+
 func (g *GuidanceDocument) guidelineToControl(guideline Guideline, resourcesMap map[string]string) (oscal.Control, string) {
 	controlId := oscalUtils.NormalizeControl(guideline.Id, false)
 
@@ -253,17 +287,21 @@ func (g *GuidanceDocument) guidelineToControl(guideline Guideline, resourcesMap
 		links = append(links, relatedLink)
 	}
 
-	for _, external := range guideline.ExternalReferences {
-		ref, found := resourcesMap[external]
-		if !found {
+	// Only principles defined in this document can be linked within the catalog
+	for _, mapping := range guideline.PrincipleMappings {
+		if mapping.ReferenceId != g.Metadata.Id {
 			continue
 		}
-		externalLink := oscal.Link{
-			Href: fmt.Sprintf("#%s", ref),
-			Rel:  "reference",
+		for _, entry := range mapping.Entries {
+			principleLink := oscal.Link{
+				Href: fmt.Sprintf("#%s", oscalUtils.NormalizeControl(entry.ReferenceId, false)),
+				Rel:  "principle",
+			}
+			links = append(links, principleLink)
 		}
-		links = append(links, externalLink)
 	}
+
+	links = append(links, externalLinks(guideline.ExternalReferences, resourcesMap)...)
 	control.Links = oscalUtils.NilIfEmpty(links)
 
 	// Top-level statements are required for controls per OSCAL guidance
@@ -322,6 +360,22 @@ func (g *GuidanceDocument) guidelineToControl(guideline Guideline, resourcesMap
 	return control, oscalUtils.NormalizeControl(guideline.BaseGuidelineID, false)
 }
 
+func externalLinks(externalReferences []string, resourcesMap map[string]string) []oscal.Link {
+	var links []oscal.Link
+	for _, external := range externalReferences {
+		ref, found := resourcesMap[external]
+		if !found {
+			continue
+		}
+		externalLink := oscal.Link{
+			Href: fmt.Sprintf("#%s", ref),
+			Rel:  "reference",
+		}
+		links = append(links, externalLink)
+	}
+	return links
+}
+
 func resourcesToBackMatter(resourceRefs []ResourceReference) *oscal.BackMatter {
 	var resources []oscal.Resource
 	for _, ref := range resourceRefs {
@@ -94,6 +94,44 @@ func TestToOSCALCatalog(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:     "Good AIGF Principles",
+			guidance: goodAIGFPrinciplesExample(),
+			wantGroups: []oscalTypes.Group{
+				{
+					Class: "principles",
+					ID:    "principles",
+					Title: "Principles",
+					Controls: &[]oscalTypes.Control{
+						{
+							Class: "principle",
+							ID:    "timeliness",
+							Title: "Timeliness",
+							Parts: &[]oscalTypes.Part{
+								{
+									Name:  "statement",
+									ID:    "timeliness_smt",
+									Prose: "Issues with AI systems are identified and acted upon quickly enough to limit their impact.",
+								},
+							},
+						},
+						{
+							Class: "principle",
+							ID:    "transparency",
+							Title: "Transparency",
+							Parts: &[]oscalTypes.Part{
+								{
+									Name:  "statement",
+									ID:    "transparency_smt",
+									Prose: "The behavior and limitations of AI systems are communicated clearly to the people who rely on them.",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name:     "Failure/EmptyGuidance",
 			guidance: GuidanceDocument{},
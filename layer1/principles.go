@@ -0,0 +1,101 @@
+package layer1
+
+import (
+	"errors"
+	"fmt"
+)
+
+// GetPrinciple returns the principle with the given id from the locally defined principles.
+func (g *GuidanceDocument) GetPrinciple(principleId string) (Principle, bool) {
+	for _, principle := range g.Principles {
+		if principle.Id == principleId {
+			return principle, true
+		}
+	}
+	return Principle{}, false
+}
+
+// GetGuideline returns the guideline with the given id from any category in the document.
+func (g *GuidanceDocument) GetGuideline(guidelineId string) (Guideline, bool) {
+	for _, category := range g.Categories {
+		for _, guideline := range category.Guidelines {
+			if guideline.Id == guidelineId {
+				return guideline, true
+			}
+		}
+	}
+	return Guideline{}, false
+}
+
+// PrinciplesForGuideline resolves the principle mappings of the guideline with the given id.
+// Mappings that reference this document are resolved against its own principles, and all other
+// mappings are resolved against the supplied principle documents by their metadata id.
+// An error is returned for any mapping entry that cannot be resolved.
+func (g *GuidanceDocument) PrinciplesForGuideline(guidelineId string, principleDocs ...GuidanceDocument) ([]Principle, error) {
+	guideline, found := g.GetGuideline(guidelineId)
+	if !found {
+		return nil, fmt.Errorf("guideline %s not found in document %s", guidelineId, g.Metadata.Id)
+	}
+	return g.resolvePrincipleMappings(guideline.PrincipleMappings, principleDocs)
+}
+
+// ResolveImportedPrinciples resolves the imported principles of the document against the
+// supplied principle documents. An error is returned for any entry that cannot be resolved.
+func (g *GuidanceDocument) ResolveImportedPrinciples(principleDocs ...GuidanceDocument) ([]Principle, error) {
+	return g.resolvePrincipleMappings(g.ImportedPrinciples, principleDocs)
+}
+
+// GuidelinesForPrinciple returns all guidelines in the document that map to the given principle.
+// The documentId is the metadata id of the document where the principle is defined.
+func (g *GuidanceDocument) GuidelinesForPrinciple(documentId, principleId string) []Guideline {
+	var guidelines []Guideline
+	for _, category := range g.Categories {
+		for _, guideline := range category.Guidelines {
+			if mapsToEntry(guideline.PrincipleMappings, documentId, principleId) {
+				guidelines = append(guidelines, guideline)
+			}
+		}
+	}
+	return guidelines
+}
+
+func (g *GuidanceDocument) resolvePrincipleMappings(mappings []Mapping, principleDocs []GuidanceDocument) ([]Principle, error) {
+	documents := make(map[string]*GuidanceDocument, len(principleDocs)+1)
+	for i := range principleDocs {
+		documents[principleDocs[i].Metadata.Id] = &principleDocs[i]
+	}
+	documents[g.Metadata.Id] = g
+
+	var principles []Principle
+	var errs []error
+	for _, mapping := range mappings {
+		doc, found := documents[mapping.ReferenceId]
+		if !found {
+			errs = append(errs, fmt.Errorf("principle document %s was not provided", mapping.ReferenceId))
+			continue
+		}
+		for _, entry := range mapping.Entries {
+			principle, found := doc.GetPrinciple(entry.ReferenceId)
+			if !found {
+				errs = append(errs, fmt.Errorf("principle %s not found in document %s", entry.ReferenceId, mapping.ReferenceId))
+				continue
+			}
+			principles = append(principles, principle)
+		}
+	}
+	return principles, errors.Join(errs...)
+}
+
+func mapsToEntry(mappings []Mapping, referenceId, entryId string) bool {
+	for _, mapping := range mappings {
+		if mapping.ReferenceId != referenceId {
+			continue
+		}
+		for _, entry := range mapping.Entries {
+			if entry.ReferenceId == entryId {
+				return true
+			}
+		}
+	}
+	return false
+}
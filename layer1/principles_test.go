@@ -0,0 +1,84 @@
+package layer1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrinciplesForGuideline(t *testing.T) {
+	localPrinciples := goodAIGFExample()
+	localPrinciples.Principles = goodAIGFPrinciplesExample().Principles
+	localPrinciples.Categories[0].Guidelines[0].PrincipleMappings = []Mapping{
+		{
+			ReferenceId: "FINOS-AIR",
+			Entries: []MappingEntry{
+				{ReferenceId: "TRANSPARENCY", Strength: 5},
+			},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		guidance       GuidanceDocument
+		guidelineId    string
+		principleDocs  []GuidanceDocument
+		wantPrinciples []string
+		wantErr        bool
+	}{
+		{
+			name:           "Success/ExternalPrinciples",
+			guidance:       goodAIGFExample(),
+			guidelineId:    "AIR-DET-011",
+			principleDocs:  []GuidanceDocument{goodAIGFPrinciplesExample()},
+			wantPrinciples: []string{"TIMELINESS"},
+		},
+		{
+			name:           "Success/LocalPrinciples",
+			guidance:       localPrinciples,
+			guidelineId:    "AIR-DET-011",
+			wantPrinciples: []string{"TRANSPARENCY"},
+		},
+		{
+			name:        "Failure/MissingPrincipleDocument",
+			guidance:    goodAIGFExample(),
+			guidelineId: "AIR-DET-011",
+			wantErr:     true,
+		},
+		{
+			name:          "Failure/UnknownGuideline",
+			guidance:      goodAIGFExample(),
+			guidelineId:   "AIR-DET-999",
+			principleDocs: []GuidanceDocument{goodAIGFPrinciplesExample()},
+			wantErr:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principles, err := tt.guidance.PrinciplesForGuideline(tt.guidelineId, tt.principleDocs...)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			var gotPrinciples []string
+			for _, principle := range principles {
+				gotPrinciples = append(gotPrinciples, principle.Id)
+			}
+			assert.Equal(t, tt.wantPrinciples, gotPrinciples)
+		})
+	}
+}
+
+func TestGuidelinesForPrinciple(t *testing.T) {
+	guidance := goodAIGFExample()
+
+	guidelines := guidance.GuidelinesForPrinciple("AIR-PRIN", "TIMELINESS")
+	require.Len(t, guidelines, 1)
+	assert.Equal(t, "AIR-DET-011", guidelines[0].Id)
+
+	assert.Empty(t, guidance.GuidelinesForPrinciple("AIR-PRIN", "TRANSPARENCY"))
+	assert.Empty(t, guidance.GuidelinesForPrinciple("OTHER-PRIN", "TIMELINESS"))
+}
@@ -132,3 +132,27 @@ func goodAIGFExample() GuidanceDocument {
 		},
 	}
 }
+
+func goodAIGFPrinciplesExample() GuidanceDocument {
+	return GuidanceDocument{
+		Metadata: Metadata{
+			Id:           "AIR-PRIN",
+			Title:        "Example Principles Document for the Framework",
+			Version:      "0.1.0",
+			DocumentType: "Framework",
+			LastModified: time.Now().Format(time.RFC3339),
+		},
+		Principles: []Principle{
+			{
+				Id:          "TIMELINESS",
+				Title:       "Timeliness",
+				Description: "Issues with AI systems are identified and acted upon quickly enough to limit their impact.",
+			},
+			{
+				Id:          "TRANSPARENCY",
+				Title:       "Transparency",
+				Description: "The behavior and limitations of AI systems are communicated clearly to the people who rely on them.",
+			},
+		},
+	}
+}
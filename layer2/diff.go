@@ -0,0 +1,261 @@
+package layer2
+
+import (
+	"github.com/ossf/gemara/internal/diffutil"
+	"github.com/ossf/gemara/layer1"
+)
+
+// DiffType describes how an entry changed between two versions of a catalog.
+type DiffType = layer1.DiffType
+
+const (
+	DiffAdded    = layer1.DiffAdded
+	DiffRemoved  = layer1.DiffRemoved
+	DiffModified = layer1.DiffModified
+)
+
+// CatalogDiff contains the differences between two versions of a Catalog.
+// Entries are matched by their ID rather than by their position in the catalog.
+type CatalogDiff struct {
+	// CatalogId is the ID of the updated catalog
+	CatalogId string `json:"catalog-id" yaml:"catalog-id"`
+	// OldVersion is the version of the original catalog
+	OldVersion string `json:"old-version,omitempty" yaml:"old-version,omitempty"`
+	// NewVersion is the version of the updated catalog
+	NewVersion string `json:"new-version,omitempty" yaml:"new-version,omitempty"`
+	// Controls lists the controls that were added, removed or modified
+	Controls []ControlDiff `json:"controls,omitempty" yaml:"controls,omitempty"`
+	// Threats lists the threats that were added, removed or modified
+	Threats []EntryDiff `json:"threats,omitempty" yaml:"threats,omitempty"`
+	// Capabilities lists the capabilities that were added, removed or modified
+	Capabilities []EntryDiff `json:"capabilities,omitempty" yaml:"capabilities,omitempty"`
+}
+
+// ControlDiff describes the changes to a single control.
+type ControlDiff struct {
+	// ControlId is the unique identifier of the control
+	ControlId string `json:"control-id" yaml:"control-id"`
+	// Family is the title of the control family containing the control
+	Family string `json:"family,omitempty" yaml:"family,omitempty"`
+	// Type describes whether the control was added, removed or modified
+	Type DiffType `json:"type" yaml:"type"`
+	// Renamed is true if the title of the control changed
+	Renamed bool `json:"renamed,omitempty" yaml:"renamed,omitempty"`
+	// Fields lists the changed fields of a modified control
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Requirements lists the assessment requirements that were added, removed or modified
+	Requirements []RequirementDiff `json:"requirements,omitempty" yaml:"requirements,omitempty"`
+	// ThreatMappings lists the threat mapping entries that were added, removed or modified
+	ThreatMappings []MappingDiff `json:"threat-mappings,omitempty" yaml:"threat-mappings,omitempty"`
+	// GuidelineMappings lists the guideline mapping entries that were added, removed or modified
+	GuidelineMappings []MappingDiff `json:"guideline-mappings,omitempty" yaml:"guideline-mappings,omitempty"`
+}
+
+// RequirementDiff describes the changes to a single assessment requirement.
+type RequirementDiff struct {
+	// RequirementId is the unique identifier of the assessment requirement
+	RequirementId string `json:"requirement-id" yaml:"requirement-id"`
+	// Type describes whether the requirement was added, removed or modified
+	Type DiffType `json:"type" yaml:"type"`
+	// Fields lists the changed fields of a modified requirement
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// ApplicabilityAdded lists the applicability categories that now apply to the requirement
+	ApplicabilityAdded []string `json:"applicability-added,omitempty" yaml:"applicability-added,omitempty"`
+	// ApplicabilityRemoved lists the applicability categories that no longer apply to the requirement
+	ApplicabilityRemoved []string `json:"applicability-removed,omitempty" yaml:"applicability-removed,omitempty"`
+}
+
+// MappingDiff describes the changes to a single mapping entry.
+type MappingDiff = layer1.MappingDiff
+
+// EntryDiff describes the changes to a threat or capability.
+type EntryDiff struct {
+	// Id is the unique identifier of the entry
+	Id string `json:"id" yaml:"id"`
+	// Type describes whether the entry was added, removed or modified
+	Type DiffType `json:"type" yaml:"type"`
+	// Fields lists the changed fields of a modified entry
+	Fields []FieldChange `json:"fields,omitempty" yaml:"fields,omitempty"`
+	// Mappings lists the capability and external mapping entries that were added, removed or modified
+	Mappings []MappingDiff `json:"mappings,omitempty" yaml:"mappings,omitempty"`
+}
+
+// FieldChange records the previous and updated value of a single field.
+type FieldChange = layer1.FieldChange
+
+// IsEmpty returns true if no differences were found.
+func (d CatalogDiff) IsEmpty() bool {
+	return len(d.Controls) == 0 && len(d.Threats) == 0 && len(d.Capabilities) == 0
+}
+
+// Diff compares the catalog with an updated version of it and returns the differences.
+// Controls, assessment requirements, threats, capabilities and mapping entries are matched by ID.
+func (c *Catalog) Diff(updated *Catalog) CatalogDiff {
+	diff := CatalogDiff{
+		CatalogId:  updated.Metadata.Id,
+		OldVersion: c.Metadata.Version,
+		NewVersion: updated.Metadata.Version,
+	}
+
+	oldControls := indexControls(c)
+	newControls := indexControls(updated)
+	for _, id := range diffutil.UnionKeys(oldControls, newControls) {
+		oldControl, inOld := oldControls[id]
+		newControl, inNew := newControls[id]
+		switch {
+		case !inOld:
+			diff.Controls = append(diff.Controls, ControlDiff{ControlId: id, Family: newControl.family, Type: DiffAdded})
+		case !inNew:
+			diff.Controls = append(diff.Controls, ControlDiff{ControlId: id, Family: oldControl.family, Type: DiffRemoved})
+		default:
+			if controlDiff, changed := diffControl(oldControl, newControl); changed {
+				diff.Controls = append(diff.Controls, controlDiff)
+			}
+		}
+	}
+
+	oldThreats := make(map[string]Threat)
+	for _, threat := range c.Threats {
+		oldThreats[threat.Id] = threat
+	}
+	newThreats := make(map[string]Threat)
+	for _, threat := range updated.Threats {
+		newThreats[threat.Id] = threat
+	}
+	for _, id := range diffutil.UnionKeys(oldThreats, newThreats) {
+		oldThreat, inOld := oldThreats[id]
+		newThreat, inNew := newThreats[id]
+		switch {
+		case !inOld:
+			diff.Threats = append(diff.Threats, EntryDiff{Id: id, Type: DiffAdded})
+		case !inNew:
+			diff.Threats = append(diff.Threats, EntryDiff{Id: id, Type: DiffRemoved})
+		default:
+			entryDiff := EntryDiff{Id: id, Type: DiffModified}
+			entryDiff.Fields = layer1.DiffFields(
+				FieldChange{Field: "title", Old: oldThreat.Title, New: newThreat.Title},
+				FieldChange{Field: "description", Old: oldThreat.Description, New: newThreat.Description},
+			)
+			entryDiff.Mappings = append(diffMappings(oldThreat.Capabilities, newThreat.Capabilities),
+				diffMappings(oldThreat.ExternalMappings, newThreat.ExternalMappings)...)
+			if len(entryDiff.Fields) > 0 || len(entryDiff.Mappings) > 0 {
+				diff.Threats = append(diff.Threats, entryDiff)
+			}
+		}
+	}
+
+	oldCapabilities := make(map[string]Capability)
+	for _, capability := range c.Capabilities {
+		oldCapabilities[capability.Id] = capability
+	}
+	newCapabilities := make(map[string]Capability)
+	for _, capability := range updated.Capabilities {
+		newCapabilities[capability.Id] = capability
+	}
+	for _, id := range diffutil.UnionKeys(oldCapabilities, newCapabilities) {
+		oldCapability, inOld := oldCapabilities[id]
+		newCapability, inNew := newCapabilities[id]
+		switch {
+		case !inOld:
+			diff.Capabilities = append(diff.Capabilities, EntryDiff{Id: id, Type: DiffAdded})
+		case !inNew:
+			diff.Capabilities = append(diff.Capabilities, EntryDiff{Id: id, Type: DiffRemoved})
+		default:
+			fields := layer1.DiffFields(
+				FieldChange{Field: "title", Old: oldCapability.Title, New: newCapability.Title},
+				FieldChange{Field: "description", Old: oldCapability.Description, New: newCapability.Description},
+			)
+			if len(fields) > 0 {
+				diff.Capabilities = append(diff.Capabilities, EntryDiff{Id: id, Type: DiffModified, Fields: fields})
+			}
+		}
+	}
+
+	return diff
+}
+
+type familyControl struct {
+	Control
+	family string
+}
+
+func indexControls(catalog *Catalog) map[string]familyControl {
+	controls := make(map[string]familyControl)
+	for _, family := range catalog.ControlFamilies {
+		for _, control := range family.Controls {
+			controls[control.Id] = familyControl{Control: control, family: family.Title}
+		}
+	}
+	return controls
+}
+
+func diffControl(oldControl, newControl familyControl) (ControlDiff, bool) {
+	diff := ControlDiff{
+		ControlId: newControl.Id,
+		Family:    newControl.family,
+		Type:      DiffModified,
+		Renamed:   diffutil.NormalizeText(oldControl.Title) != diffutil.NormalizeText(newControl.Title),
+	}
+	diff.Fields = layer1.DiffFields(
+		FieldChange{Field: "title", Old: oldControl.Title, New: newControl.Title},
+		FieldChange{Field: "objective", Old: oldControl.Objective, New: newControl.Objective},
+		FieldChange{Field: "family", Old: oldControl.family, New: newControl.family},
+	)
+
+	oldRequirements := make(map[string]AssessmentRequirement)
+	for _, requirement := range oldControl.AssessmentRequirements {
+		oldRequirements[requirement.Id] = requirement
+	}
+	newRequirements := make(map[string]AssessmentRequirement)
+	for _, requirement := range newControl.AssessmentRequirements {
+		newRequirements[requirement.Id] = requirement
+	}
+	for _, id := range diffutil.UnionKeys(oldRequirements, newRequirements) {
+		oldRequirement, inOld := oldRequirements[id]
+		newRequirement, inNew := newRequirements[id]
+		switch {
+		case !inOld:
+			diff.Requirements = append(diff.Requirements, RequirementDiff{RequirementId: id, Type: DiffAdded})
+		case !inNew:
+			diff.Requirements = append(diff.Requirements, RequirementDiff{RequirementId: id, Type: DiffRemoved})
+		default:
+			requirementDiff := RequirementDiff{
+				RequirementId: id,
+				Type:          DiffModified,
+				Fields: layer1.DiffFields(
+					FieldChange{Field: "text", Old: oldRequirement.Text, New: newRequirement.Text},
+					FieldChange{Field: "recommendation", Old: oldRequirement.Recommendation, New: newRequirement.Recommendation},
+				),
+				ApplicabilityAdded:   diffutil.Difference(newRequirement.Applicability, oldRequirement.Applicability),
+				ApplicabilityRemoved: diffutil.Difference(oldRequirement.Applicability, newRequirement.Applicability),
+			}
+			if len(requirementDiff.Fields) > 0 || len(requirementDiff.ApplicabilityAdded) > 0 || len(requirementDiff.ApplicabilityRemoved) > 0 {
+				diff.Requirements = append(diff.Requirements, requirementDiff)
+			}
+		}
+	}
+
+	diff.ThreatMappings = diffMappings(oldControl.ThreatMappings, newControl.ThreatMappings)
+	diff.GuidelineMappings = diffMappings(oldControl.GuidelineMappings, newControl.GuidelineMappings)
+
+	changed := len(diff.Fields) > 0 || len(diff.Requirements) > 0 || len(diff.ThreatMappings) > 0 || len(diff.GuidelineMappings) > 0
+	return diff, changed
+}
+
+// guidanceMappings converts catalog mappings to guidance mappings, which have the same fields,
+// so that they can be compared with layer1.DiffMappings.
+func guidanceMappings(mappings []Mapping) []layer1.Mapping {
+	converted := make([]layer1.Mapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		entries := make([]layer1.MappingEntry, 0, len(mapping.Entries))
+		for _, entry := range mapping.Entries {
+			entries = append(entries, layer1.MappingEntry(entry))
+		}
+		converted = append(converted, layer1.Mapping{ReferenceId: mapping.ReferenceId, Entries: entries, Remarks: mapping.Remarks})
+	}
+	return converted
+}
+
+func diffMappings(oldMappings, newMappings []Mapping) []MappingDiff {
+	return layer1.DiffMappings(guidanceMappings(oldMappings), guidanceMappings(newMappings))
+}
@@ -0,0 +1,151 @@
+package layer2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadDiffCatalog(t *testing.T) *Catalog {
+	c := &Catalog{}
+	require.NoError(t, c.LoadFile("./test-data/good-ccc.yaml"))
+	return c
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		update func(c *Catalog)
+		want   func(t *testing.T, diff CatalogDiff)
+	}{
+		{
+			name:   "No changes",
+			update: func(c *Catalog) {},
+			want: func(t *testing.T, diff CatalogDiff) {
+				assert.True(t, diff.IsEmpty())
+			},
+		},
+		{
+			name: "Whitespace changes are ignored",
+			update: func(c *Catalog) {
+				c.ControlFamilies[0].Controls[0].Objective += "\n\n"
+			},
+			want: func(t *testing.T, diff CatalogDiff) {
+				assert.True(t, diff.IsEmpty())
+			},
+		},
+		{
+			name: "Whitespace changes to applicability are ignored",
+			update: func(c *Catalog) {
+				requirement := &c.ControlFamilies[0].Controls[0].AssessmentRequirements[0]
+				for i := range requirement.Applicability {
+					requirement.Applicability[i] += "\n"
+				}
+			},
+			want: func(t *testing.T, diff CatalogDiff) {
+				assert.True(t, diff.IsEmpty())
+			},
+		},
+		{
+			name: "Control added and removed",
+			update: func(c *Catalog) {
+				family := &c.ControlFamilies[0]
+				family.Controls[0].Id = "CCC.C99"
+			},
+			want: func(t *testing.T, diff CatalogDiff) {
+				require.Len(t, diff.Controls, 2)
+				assert.Equal(t, ControlDiff{ControlId: "CCC.C01", Family: "Data Protection", Type: DiffRemoved}, diff.Controls[0])
+				assert.Equal(t, ControlDiff{ControlId: "CCC.C99", Family: "Data Protection", Type: DiffAdded}, diff.Controls[1])
+			},
+		},
+		{
+			name: "Control renamed and moved to another family",
+			update: func(c *Catalog) {
+				control := c.ControlFamilies[0].Controls[0]
+				control.Title = "Require Encrypted Requests"
+				c.ControlFamilies[0].Controls = c.ControlFamilies[0].Controls[1:]
+				c.ControlFamilies = append(c.ControlFamilies, ControlFamily{
+					Id:       "encryption",
+					Title:    "Encryption",
+					Controls: []Control{control},
+				})
+			},
+			want: func(t *testing.T, diff CatalogDiff) {
+				require.Len(t, diff.Controls, 1)
+				assert.Equal(t, "CCC.C01", diff.Controls[0].ControlId)
+				assert.Equal(t, DiffModified, diff.Controls[0].Type)
+				assert.True(t, diff.Controls[0].Renamed)
+				assert.Equal(t, []FieldChange{
+					{Field: "title", Old: "Prevent Unencrypted Requests", New: "Require Encrypted Requests"},
+					{Field: "family", Old: "Data Protection", New: "Encryption"},
+				}, diff.Controls[0].Fields)
+			},
+		},
+		{
+			name: "Requirement text and applicability changed",
+			update: func(c *Catalog) {
+				requirement := &c.ControlFamilies[0].Controls[0].AssessmentRequirements[0]
+				requirement.Text = "All traffic MUST be encrypted using TLS 1.3."
+				requirement.Applicability = []string{"tlp_green", "tlp_amber", "tlp_red", "tlp_black"}
+			},
+			want: func(t *testing.T, diff CatalogDiff) {
+				require.Len(t, diff.Controls, 1)
+				require.Len(t, diff.Controls[0].Requirements, 1)
+				requirement := diff.Controls[0].Requirements[0]
+				assert.Equal(t, "CCC.C01.TR01", requirement.RequirementId)
+				assert.Equal(t, DiffModified, requirement.Type)
+				require.Len(t, requirement.Fields, 1)
+				assert.Equal(t, "text", requirement.Fields[0].Field)
+				assert.Equal(t, []string{"tlp_black"}, requirement.ApplicabilityAdded)
+				assert.Equal(t, []string{"tlp_clear"}, requirement.ApplicabilityRemoved)
+				assert.False(t, diff.Controls[0].Renamed)
+			},
+		},
+		{
+			name: "Mappings changed",
+			update: func(c *Catalog) {
+				control := &c.ControlFamilies[0].Controls[0]
+				control.ThreatMappings[0].Entries[0].Strength = 9
+				control.GuidelineMappings = control.GuidelineMappings[1:]
+				control.GuidelineMappings[0].Entries = append(control.GuidelineMappings[0].Entries, MappingEntry{ReferenceId: "IVS-09", Strength: 5})
+			},
+			want: func(t *testing.T, diff CatalogDiff) {
+				require.Len(t, diff.Controls, 1)
+				assert.Equal(t, []MappingDiff{
+					{ReferenceId: "CCC", EntryId: "CCC.TH02", Type: DiffModified, Fields: []FieldChange{{Field: "strength", Old: "7", New: "9"}}},
+				}, diff.Controls[0].ThreatMappings)
+				assert.Equal(t, []MappingDiff{
+					{ReferenceId: "CCM", EntryId: "IVS-09", Type: DiffAdded},
+					{ReferenceId: "CSF", EntryId: "PR.DS-02", Type: DiffRemoved},
+				}, diff.Controls[0].GuidelineMappings)
+			},
+		},
+		{
+			name: "Threats and capabilities changed",
+			update: func(c *Catalog) {
+				c.Threats = append(c.Threats, Threat{Id: "CCC.TH99", Title: "New Threat"})
+				c.Capabilities = append(c.Capabilities, Capability{Id: "CCC.F99", Title: "New Capability"})
+			},
+			want: func(t *testing.T, diff CatalogDiff) {
+				assert.Empty(t, diff.Controls)
+				assert.Equal(t, []EntryDiff{{Id: "CCC.TH99", Type: DiffAdded}}, diff.Threats)
+				assert.Equal(t, []EntryDiff{{Id: "CCC.F99", Type: DiffAdded}}, diff.Capabilities)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := loadDiffCatalog(t)
+			updated := loadDiffCatalog(t)
+			updated.Metadata.Version = "2.0.0"
+			tt.update(updated)
+
+			diff := original.Diff(updated)
+			assert.Equal(t, "FINOS-CCC", diff.CatalogId)
+			assert.Equal(t, "2.0.0", diff.NewVersion)
+			tt.want(t, diff)
+		})
+	}
+}
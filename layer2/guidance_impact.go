@@ -0,0 +1,63 @@
+package layer2
+
+import (
+	"github.com/ossf/gemara/layer1"
+)
+
+// MappingImpact identifies a guideline mapping entry in a catalog that points to a guideline
+// which was removed, modified or renumbered in a new version of the referenced guidance document.
+type MappingImpact struct {
+	// CatalogId is the ID of the catalog containing the mapping
+	CatalogId string `json:"catalog-id" yaml:"catalog-id"`
+	// ControlId is the ID of the control containing the mapping
+	ControlId string `json:"control-id" yaml:"control-id"`
+	// ReferenceId is the ID of the mapping reference for the guidance document
+	ReferenceId string `json:"reference-id" yaml:"reference-id"`
+	// GuidelineId is the ID of the mapped guideline
+	GuidelineId string `json:"guideline-id" yaml:"guideline-id"`
+	// Type describes whether the mapped guideline was removed, modified or renumbered
+	Type layer1.DiffType `json:"type" yaml:"type"`
+	// NewGuidelineId is the ID of a renumbered guideline in the updated guidance document
+	NewGuidelineId string `json:"new-guideline-id,omitempty" yaml:"new-guideline-id,omitempty"`
+}
+
+// GuidanceImpacts returns the guideline mapping entries in the catalogs that point to guidelines
+// which were removed, modified or renumbered in the guidance diff. The referenceId is the ID
+// the catalogs use for the guidance document in their mapping references; if empty, the ID
+// of the guidance document is used.
+func GuidanceImpacts(diff layer1.GuidanceDiff, referenceId string, catalogs ...Catalog) []MappingImpact {
+	if referenceId == "" {
+		referenceId = diff.DocumentId
+	}
+
+	var impacts []MappingImpact
+	for _, catalog := range catalogs {
+		for _, family := range catalog.ControlFamilies {
+			for _, control := range family.Controls {
+				for _, mapping := range control.GuidelineMappings {
+					if mapping.ReferenceId != referenceId {
+						continue
+					}
+					for _, entry := range mapping.Entries {
+						guidelineDiff, found := diff.GetGuideline(entry.ReferenceId)
+						if !found {
+							continue
+						}
+						impact := MappingImpact{
+							CatalogId:   catalog.Metadata.Id,
+							ControlId:   control.Id,
+							ReferenceId: mapping.ReferenceId,
+							GuidelineId: entry.ReferenceId,
+							Type:        guidelineDiff.Type,
+						}
+						if guidelineDiff.Type == layer1.DiffRenumbered {
+							impact.NewGuidelineId = guidelineDiff.GuidelineId
+						}
+						impacts = append(impacts, impact)
+					}
+				}
+			}
+		}
+	}
+	return impacts
+}
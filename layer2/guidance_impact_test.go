@@ -0,0 +1,29 @@
+package layer2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ossf/gemara/layer1"
+)
+
+func TestGuidanceImpacts(t *testing.T) {
+	catalog := loadDiffCatalog(t)
+	diff := layer1.GuidanceDiff{
+		DocumentId: "CSA-CCM",
+		Guidelines: []layer1.GuidelineDiff{
+			{GuidelineId: "IVS-03", Type: layer1.DiffRemoved},
+			{GuidelineId: "DSI-06(1)", PreviousId: "DSI-06", Type: layer1.DiffRenumbered},
+			{GuidelineId: "DSI-99", Type: layer1.DiffAdded},
+		},
+	}
+
+	impacts := GuidanceImpacts(diff, "CCM", *catalog)
+	assert.Equal(t, []MappingImpact{
+		{CatalogId: "FINOS-CCC", ControlId: "CCC.C01", ReferenceId: "CCM", GuidelineId: "IVS-03", Type: layer1.DiffRemoved},
+		{CatalogId: "FINOS-CCC", ControlId: "CCC.C06", ReferenceId: "CCM", GuidelineId: "DSI-06", Type: layer1.DiffRenumbered, NewGuidelineId: "DSI-06(1)"},
+	}, impacts)
+
+	assert.Empty(t, GuidanceImpacts(diff, "", *catalog), "mappings should be matched by the document ID by default")
+}
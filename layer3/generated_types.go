@@ -204,4 +204,31 @@ type PartModifier struct {
 	Recommendations	[]string	`json:"recommendations,omitempty" yaml:"recommendations,omitempty"`
 }
 
+// Waivers accept the risk of failing evaluation results until they expire
+type WaiverDocument struct {
+	Metadata	Metadata	`json:"metadata" yaml:"metadata"`
+
+	Waivers	[]Waiver	`json:"waivers" yaml:"waivers"`
+}
+
+type Waiver struct {
+	Id	string	`json:"id" yaml:"id"`
+
+	ControlId	string	`json:"control-id" yaml:"control-id"`
+
+	// The assessment requirements covered by the waiver. All requirements of the control are covered if none are listed.
+	RequirementIds	[]string	`json:"requirement-ids,omitempty" yaml:"requirement-ids,omitempty"`
+
+	// The evaluation target covered by the waiver, such as a repository or account name. All targets are covered if omitted.
+	Target	string	`json:"target,omitempty" yaml:"target,omitempty"`
+
+	Justification	string	`json:"justification" yaml:"justification"`
+
+	Approver	Contact	`json:"approver" yaml:"approver"`
+
+	ApprovedOn	Datetime	`json:"approved-on,omitempty" yaml:"approved-on,omitempty"`
+
+	Expires	Datetime	`json:"expires" yaml:"expires"`
+}
+
 type Email string
@@ -0,0 +1,287 @@
+package layer4
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// ApplicabilityExpression is a parsed applicability expression, which determines whether an assessment applies
+// to a set of facts such as maturity levels or repository properties.
+//
+// Facts are combined with the AND, OR and NOT operators and grouped with parentheses, such as
+// "Maturity Level 2 AND tlp_green" or "NOT archived". NOT binds tighter than AND, which binds tighter than OR.
+// A fact is any sequence of words other than the operators, matched exactly against the provided facts, and may be
+// quoted to include an operator or parenthesis. A plain fact name is therefore also a valid expression.
+type ApplicabilityExpression struct {
+	expression string
+	root       applicabilityNode
+}
+
+type applicabilityNode interface {
+	applies(facts map[string]bool) bool
+}
+
+type factNode string
+
+func (n factNode) applies(facts map[string]bool) bool {
+	return facts[string(n)]
+}
+
+type notNode struct {
+	operand applicabilityNode
+}
+
+func (n notNode) applies(facts map[string]bool) bool {
+	return !n.operand.applies(facts)
+}
+
+type andNode []applicabilityNode
+
+func (n andNode) applies(facts map[string]bool) bool {
+	for _, operand := range n {
+		if !operand.applies(facts) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []applicabilityNode
+
+func (n orNode) applies(facts map[string]bool) bool {
+	for _, operand := range n {
+		if operand.applies(facts) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseApplicability parses an applicability expression.
+func ParseApplicability(expression string) (*ApplicabilityExpression, error) {
+	tokens, err := tokenizeApplicability(expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid applicability expression %q: %w", expression, err)
+	}
+	parser := &applicabilityParser{tokens: tokens}
+	root, err := parser.parseOr()
+	if err == nil && parser.pos < len(tokens) {
+		err = fmt.Errorf("unexpected %q", tokens[parser.pos].value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid applicability expression %q: %w", expression, err)
+	}
+	return &ApplicabilityExpression{expression: expression, root: root}, nil
+}
+
+// Applies returns true if the expression holds for the facts.
+func (e *ApplicabilityExpression) Applies(facts []string) bool {
+	return e.root.applies(factSet(facts))
+}
+
+func (e *ApplicabilityExpression) String() string {
+	return e.expression
+}
+
+// IsApplicable returns true if any of the applicability expressions holds for the facts.
+// Each entry is first matched exactly against the facts, and is only parsed as an expression if it does not match
+// and contains an operator. Entries without operators are plain fact names, even if they contain parentheses or
+// quotes, so that applicability written before expressions were supported keeps its meaning.
+// It returns an error if any expression is invalid.
+func IsApplicable(applicability []string, facts []string) (bool, error) {
+	set := factSet(facts)
+	applicable := false
+	for _, expression := range applicability {
+		if set[expression] {
+			applicable = true
+			continue
+		}
+		if !hasApplicabilityOperator(expression) {
+			continue
+		}
+		parsed, err := ParseApplicability(expression)
+		if err != nil {
+			return false, err
+		}
+		if parsed.root.applies(set) {
+			applicable = true
+		}
+	}
+	return applicable, nil
+}
+
+// hasApplicabilityOperator returns true if the expression contains an AND, OR or NOT operator.
+func hasApplicabilityOperator(expression string) bool {
+	words := strings.FieldsFunc(expression, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`()"`, r)
+	})
+	for _, word := range words {
+		if _, found := applicabilityOperators[word]; found {
+			return true
+		}
+	}
+	return false
+}
+
+func factSet(facts []string) map[string]bool {
+	set := make(map[string]bool, len(facts))
+	for _, fact := range facts {
+		set[fact] = true
+	}
+	return set
+}
+
+type tokenKind int
+
+const (
+	factToken tokenKind = iota
+	andToken
+	orToken
+	notToken
+	openToken
+	closeToken
+)
+
+type applicabilityToken struct {
+	kind  tokenKind
+	value string
+}
+
+var applicabilityOperators = map[string]tokenKind{"AND": andToken, "OR": orToken, "NOT": notToken}
+
+// tokenizeApplicability splits an expression into operators, parentheses and facts.
+// Consecutive words that are not operators form a single fact.
+func tokenizeApplicability(expression string) ([]applicabilityToken, error) {
+	var tokens []applicabilityToken
+	var words []string
+	flush := func() {
+		if len(words) > 0 {
+			tokens = append(tokens, applicabilityToken{kind: factToken, value: strings.Join(words, " ")})
+			words = nil
+		}
+	}
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')':
+			flush()
+			kind := openToken
+			if r == ')' {
+				kind = closeToken
+			}
+			tokens = append(tokens, applicabilityToken{kind: kind, value: string(r)})
+			i++
+		case r == '"':
+			flush()
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, errors.New("unterminated quoted fact")
+			}
+			tokens = append(tokens, applicabilityToken{kind: factToken, value: string(runes[i+1 : end])})
+			i = end + 1
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"`, runes[end]) {
+				end++
+			}
+			word := string(runes[i:end])
+			if kind, found := applicabilityOperators[word]; found {
+				flush()
+				tokens = append(tokens, applicabilityToken{kind: kind, value: word})
+			} else {
+				words = append(words, word)
+			}
+			i = end
+		}
+	}
+	flush()
+	if len(tokens) == 0 {
+		return nil, errors.New("empty expression")
+	}
+	return tokens, nil
+}
+
+type applicabilityParser struct {
+	tokens []applicabilityToken
+	pos    int
+}
+
+func (p *applicabilityParser) next(kind tokenKind) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *applicabilityParser) parseOr() (applicabilityNode, error) {
+	operands := orNode{}
+	for {
+		operand, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if !p.next(orToken) {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *applicabilityParser) parseAnd() (applicabilityNode, error) {
+	operands := andNode{}
+	for {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+		if !p.next(andToken) {
+			break
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return operands, nil
+}
+
+func (p *applicabilityParser) parseUnary() (applicabilityNode, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, errors.New("unexpected end of expression")
+	}
+	token := p.tokens[p.pos]
+	p.pos++
+	switch token.kind {
+	case notToken:
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	case openToken:
+		operand, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(closeToken) {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		return operand, nil
+	case factToken:
+		return factNode(token.value), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q", token.value)
+	}
+}
@@ -0,0 +1,110 @@
+package layer4
+
+import (
+	"testing"
+)
+
+func TestParseApplicability(t *testing.T) {
+	facts := []string{"Maturity Level 2", "tlp_green", "Research AND Development"}
+	tests := []struct {
+		expression string
+		applies    bool
+		invalid    bool
+	}{
+		{expression: "Maturity Level 2", applies: true},
+		{expression: "Maturity Level 1", applies: false},
+		{expression: "Maturity Level 2 AND tlp_green", applies: true},
+		{expression: "Maturity Level 2 AND tlp_red", applies: false},
+		{expression: "Maturity Level 1 OR Maturity Level 2", applies: true},
+		{expression: "NOT archived", applies: true},
+		{expression: "NOT tlp_green", applies: false},
+		{expression: "Maturity Level 1 OR Maturity Level 2 AND NOT tlp_green", applies: false},
+		{expression: "(Maturity Level 1 OR Maturity Level 2) AND NOT archived", applies: true},
+		{expression: "NOT (tlp_green OR archived)", applies: false},
+		{expression: `"Research AND Development"`, applies: true},
+		{expression: "", invalid: true},
+		{expression: "Maturity Level 2 AND", invalid: true},
+		{expression: "(tlp_green", invalid: true},
+		{expression: "tlp_green)", invalid: true},
+		{expression: `"tlp_green`, invalid: true},
+		{expression: "AND tlp_green", invalid: true},
+	}
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			expression, err := ParseApplicability(test.expression)
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected an error for %q", test.expression)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if applies := expression.Applies(facts); applies != test.applies {
+				t.Errorf("expected %q to apply to %v: %t, got %t", test.expression, facts, test.applies, applies)
+			}
+		})
+	}
+}
+
+func TestIsApplicable(t *testing.T) {
+	applicable, err := IsApplicable([]string{"Maturity Level 1", "Maturity Level 2 AND tlp_green"}, []string{"Maturity Level 2", "tlp_green"})
+	if err != nil || !applicable {
+		t.Errorf("expected any matching expression to apply, got %t with error %v", applicable, err)
+	}
+	if _, err := IsApplicable([]string{"Maturity Level 1", "OR"}, nil); err == nil {
+		t.Error("expected an error for an invalid expression")
+	}
+}
+
+func TestIsApplicableLegacyFacts(t *testing.T) {
+	facts := []string{"Maturity Level 2 (Optional)", `Tier "Gold"`, "Research AND Development (R&D)", "Maturity  Level 3"}
+	tests := []struct {
+		applicability string
+		applies       bool
+	}{
+		{applicability: "Maturity Level 2 (Optional)", applies: true},
+		{applicability: "Maturity Level 1 (Optional)", applies: false},
+		{applicability: `Tier "Gold"`, applies: true},
+		{applicability: `Tier "Silver`, applies: false},
+		{applicability: "Research AND Development (R&D)", applies: true},
+		{applicability: "Maturity  Level 3", applies: true},
+		{applicability: "Maturity Level 3", applies: false},
+	}
+	for _, test := range tests {
+		t.Run(test.applicability, func(t *testing.T) {
+			applicable, err := IsApplicable([]string{test.applicability}, facts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if applicable != test.applies {
+				t.Errorf("expected %q to apply to %v: %t, got %t", test.applicability, facts, test.applies, applicable)
+			}
+		})
+	}
+}
+
+func TestEvaluateNotApplicable(t *testing.T) {
+	evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+	step := func(interface{}, map[string]*Change) (Result, string) { return Passed, "passed" }
+	applicable := evaluation.AddAssessment("OSPS-AC-01.01", "Applicable", []string{"Maturity Level 2 AND NOT archived"}, []AssessmentStep{step})
+	skipped := evaluation.AddAssessment("OSPS-AC-01.02", "Not applicable", []string{"Maturity Level 3"}, []AssessmentStep{step})
+	invalid := evaluation.AddAssessment("OSPS-AC-01.03", "Invalid", []string{"Maturity Level 3 AND"}, []AssessmentStep{step})
+
+	evaluation.Evaluate(nil, []string{"Maturity Level 2"}, false)
+
+	if applicable.Result != Passed || skipped.Result != NotApplicable || invalid.Result != Unknown {
+		t.Errorf("expected Passed, Not Applicable and Unknown, got %s, %s and %s", applicable.Result, skipped.Result, invalid.Result)
+	}
+	if evaluation.Result != Unknown {
+		t.Errorf("expected control result Unknown, got %s", evaluation.Result)
+	}
+
+	evaluation = &ControlEvaluation{ControlID: "OSPS-AC-02"}
+	evaluation.AddAssessment("OSPS-AC-02.01", "Not applicable", []string{"Maturity Level 3"}, []AssessmentStep{step})
+	evaluation.Evaluate(nil, []string{"Maturity Level 2"}, false)
+	if evaluation.Result != NotApplicable {
+		t.Errorf("expected a control without applicable assessments to be Not Applicable, got %s", evaluation.Result)
+	}
+}
@@ -4,51 +4,88 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"reflect"
-	"runtime"
 	"time"
+
+	"github.com/goccy/go-yaml"
 )
 
 // Assessment is a struct that contains the results of a single step within a ControlEvaluation.
 type Assessment struct {
 	// RequirementID is the unique identifier for the requirement being tested
-	RequirementId string `yaml:"requirement-id"`
+	RequirementId string `json:"requirement-id" yaml:"requirement-id"`
 	// Applicability is a slice of identifier strings to determine when this test is applicable
-	Applicability []string `yaml:"applicability"`
+	Applicability []string `json:"applicability" yaml:"applicability"`
 	// Description is a human-readable description of the test
-	Description string `yaml:"description"`
+	Description string `json:"description" yaml:"description"`
 	// Result is true if the test passed
-	Result Result `yaml:"result"`
+	Result Result `json:"result" yaml:"result"`
 	// Message is the human-readable result of the test
-	Message string `yaml:"message"`
+	Message string `json:"message" yaml:"message"`
 	// Steps is a slice of steps that were executed during the test
-	Steps []AssessmentStep `yaml:"steps"`
+	Steps []AssessmentStep `json:"steps" yaml:"steps"`
+	// StepDetails identifies the steps that have a registered or explicit ID.
+	// It is derived from Steps when the assessment is serialized, and restored when it is loaded.
+	StepDetails []StepInfo `json:"step-details,omitempty" yaml:"step-details,omitempty"`
 	// StepsExecuted is the number of steps that were executed during the test
-	StepsExecuted int `yaml:"steps-executed,omitempty"`
+	StepsExecuted int `json:"steps-executed,omitempty" yaml:"steps-executed,omitempty"`
+	// StepDurations is the time taken by each executed step in seconds, in the order the steps were executed
+	StepDurations []float64 `json:"step-durations,omitempty" yaml:"step-durations,omitempty"`
 	// Start is the time the assessment run began.
-	Start string `yaml:"start"`
+	Start string `json:"start" yaml:"start"`
 	// End is the time the assessment run finished.
 	// This is omitted if the assessment was interrupted or did not complete.
-	End string `yaml:"end,omitempty"`
+	End string `json:"end,omitempty" yaml:"end,omitempty"`
 	// Value is the object that was returned during the test
-	Value interface{} `yaml:"value,omitempty"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
 	// Changes is a slice of changes that were made during the test
-	Changes map[string]*Change `yaml:"changes,omitempty"`
+	Changes map[string]*Change `json:"changes,omitempty" yaml:"changes,omitempty"`
+	// Evidence contains the artefacts collected during the test to support its result
+	Evidence []*Evidence `json:"evidence,omitempty" yaml:"evidence,omitempty"`
 	// Recommendation is a string to aid users in remediation, such as the text from a layer 2 assessment requirement
-	Recommendation string `yaml:"recommendation,omitempty"`
+	Recommendation string `json:"recommendation,omitempty" yaml:"recommendation,omitempty"`
+	// Waiver records the waiver that covered a failing result, if any
+	Waiver *AssessmentWaiver `json:"waiver,omitempty" yaml:"waiver,omitempty"`
+	// Attestation records the manual attestation that decided a result needing review, if any
+	Attestation *AssessmentAttestation `json:"attestation,omitempty" yaml:"attestation,omitempty"`
+
+	// stepNames holds the names of the steps for assessments loaded from a file, as steps cannot be restored from their names
+	stepNames []string
+	// redactor removes secrets from the assessment when it is serialized
+	redactor *Redactor
+}
+
+// assessmentRecord is the serialized form of an Assessment, with steps recorded by name.
+type assessmentRecord struct {
+	RequirementId  string                 `json:"requirement-id" yaml:"requirement-id"`
+	Applicability  []string               `json:"applicability" yaml:"applicability"`
+	Description    string                 `json:"description" yaml:"description"`
+	Result         Result                 `json:"result" yaml:"result"`
+	Message        string                 `json:"message" yaml:"message"`
+	Steps          []string               `json:"steps" yaml:"steps"`
+	StepDetails    []StepInfo             `json:"step-details,omitempty" yaml:"step-details,omitempty"`
+	StepsExecuted  int                    `json:"steps-executed,omitempty" yaml:"steps-executed,omitempty"`
+	StepDurations  []float64              `json:"step-durations,omitempty" yaml:"step-durations,omitempty"`
+	Start          string                 `json:"start" yaml:"start"`
+	End            string                 `json:"end,omitempty" yaml:"end,omitempty"`
+	Value          interface{}            `json:"value,omitempty" yaml:"value,omitempty"`
+	Changes        map[string]*Change     `json:"changes,omitempty" yaml:"changes,omitempty"`
+	Evidence       []*Evidence            `json:"evidence,omitempty" yaml:"evidence,omitempty"`
+	Recommendation string                 `json:"recommendation,omitempty" yaml:"recommendation,omitempty"`
+	Waiver         *AssessmentWaiver      `json:"waiver,omitempty" yaml:"waiver,omitempty"`
+	Attestation    *AssessmentAttestation `json:"attestation,omitempty" yaml:"attestation,omitempty"`
 }
 
 // AssessmentStep is a function type that inspects the provided targetData and returns a Result with a message.
 // The message may be an error string or other descriptive text.
 type AssessmentStep func(payload interface{}, c map[string]*Change) (Result, string)
 
+// String returns the ID of the step if it was registered or created with NewStep, and otherwise its function name.
 func (as AssessmentStep) String() string {
-	// Get the function pointer correctly
-	fn := runtime.FuncForPC(reflect.ValueOf(as).Pointer())
-	if fn == nil {
-		return "<unknown function>"
+	name, info := as.resolve()
+	if info != nil {
+		return info.Id
 	}
-	return fn.Name()
+	return name
 }
 
 func (as AssessmentStep) MarshalJSON() ([]byte, error) {
@@ -59,6 +96,136 @@ func (as AssessmentStep) MarshalYAML() (interface{}, error) {
 	return as.String(), nil
 }
 
+// StepNames returns the names of the steps in the assessment.
+// For assessments loaded from a file, these are the names that were recorded when the assessment was written.
+func (a *Assessment) StepNames() []string {
+	if len(a.Steps) == 0 {
+		return a.stepNames
+	}
+	names := make([]string, 0, len(a.Steps))
+	for _, step := range a.Steps {
+		names = append(names, step.String())
+	}
+	return names
+}
+
+func (a *Assessment) toRecord() assessmentRecord {
+	details := a.StepDetails
+	if len(a.Steps) > 0 {
+		details = stepDetails(a.Steps)
+	}
+	return assessmentRecord{
+		RequirementId:  a.RequirementId,
+		Applicability:  a.Applicability,
+		Description:    a.Description,
+		Result:         a.Result,
+		Message:        a.Message,
+		Steps:          a.StepNames(),
+		StepDetails:    details,
+		StepsExecuted:  a.StepsExecuted,
+		StepDurations:  a.StepDurations,
+		Start:          a.Start,
+		End:            a.End,
+		Value:          a.Value,
+		Changes:        a.Changes,
+		Evidence:       a.Evidence,
+		Recommendation: a.Recommendation,
+		Waiver:         a.Waiver,
+		Attestation:    a.Attestation,
+	}
+}
+
+func (a *Assessment) fromRecord(record assessmentRecord) {
+	*a = Assessment{
+		RequirementId:  record.RequirementId,
+		Applicability:  record.Applicability,
+		Description:    record.Description,
+		Result:         record.Result,
+		Message:        record.Message,
+		StepsExecuted:  record.StepsExecuted,
+		StepDurations:  record.StepDurations,
+		Start:          record.Start,
+		End:            record.End,
+		Value:          record.Value,
+		Changes:        record.Changes,
+		Evidence:       record.Evidence,
+		Recommendation: record.Recommendation,
+		Waiver:         record.Waiver,
+		Attestation:    record.Attestation,
+		StepDetails:    record.StepDetails,
+		stepNames:      record.Steps,
+	}
+}
+
+// redactedRecord returns the serialized form of the assessment in the format, "json" or "yaml",
+// with its Redactor applied.
+func (a *Assessment) redactedRecord(format string) assessmentRecord {
+	record := a.toRecord()
+	if a.redactor == nil {
+		return record
+	}
+	record.Message = a.RedactedMessage()
+	record.Value = a.redactor.redactValue(format, "value", record.Value)
+	if record.Waiver != nil {
+		waiver := *record.Waiver
+		waiver.Justification = a.redactor.RedactString("waiver.justification", waiver.Justification)
+		record.Waiver = &waiver
+	}
+	if record.Attestation != nil {
+		attestation := *record.Attestation
+		attestation.Comment = a.redactor.RedactString("attestation.comment", attestation.Comment)
+		record.Attestation = &attestation
+	}
+	return record
+}
+
+// setRedactor sets the Redactor of the assessment and its changes.
+func (a *Assessment) setRedactor(r *Redactor) {
+	a.redactor = r
+	for _, change := range a.Changes {
+		if change != nil {
+			change.redactor = r
+		}
+	}
+}
+
+// RedactedMessage returns the message with secrets redacted, for reports that do not serialize the assessment.
+func (a *Assessment) RedactedMessage() string {
+	return a.redactor.RedactString("message", a.Message)
+}
+
+// MarshalYAML serializes the assessment with its steps recorded by name
+func (a *Assessment) MarshalYAML() (interface{}, error) {
+	return a.redactedRecord("yaml"), nil
+}
+
+// UnmarshalYAML deserializes an assessment written by MarshalYAML.
+// Steps cannot be restored from their names, so the loaded assessment can be reported on but not run.
+func (a *Assessment) UnmarshalYAML(data []byte) error {
+	var record assessmentRecord
+	if err := yaml.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	a.fromRecord(record)
+	return nil
+}
+
+// MarshalJSON serializes the assessment with its steps recorded by name
+func (a *Assessment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.redactedRecord("json"))
+}
+
+// UnmarshalJSON deserializes an assessment written by MarshalJSON.
+// Steps cannot be restored from their names, so the loaded assessment can be reported on but not run.
+func (a *Assessment) UnmarshalJSON(data []byte) error {
+	var record assessmentRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	a.fromRecord(record)
+	return nil
+}
+
 // NewAssessment creates a new Assessment object and returns a pointer to it.
 func NewAssessment(requirementId string, description string, applicability []string, steps []AssessmentStep) (*Assessment, error) {
 	a := &Assessment{
@@ -67,6 +234,7 @@ func NewAssessment(requirementId string, description string, applicability []str
 		Applicability: applicability,
 		Result:        NotRun,
 		Steps:         steps,
+		StepDetails:   stepDetails(steps),
 	}
 	err := a.precheck()
 	return a, err
@@ -75,18 +243,24 @@ func NewAssessment(requirementId string, description string, applicability []str
 // AddStep queues a new step in the Assessment
 func (a *Assessment) AddStep(step AssessmentStep) {
 	a.Steps = append(a.Steps, step)
+	if info, found := step.Info(); found {
+		a.StepDetails = append(a.StepDetails, info)
+	}
 }
 
 func (a *Assessment) runStep(targetData interface{}, step AssessmentStep) Result {
 	a.StepsExecuted++
+	start := time.Now()
 	result, message := step(targetData, a.Changes)
+	a.StepDurations = append(a.StepDurations, time.Since(start).Seconds())
 	a.Result = UpdateAggregateResult(a.Result, result)
 	a.Message = message
 	return result
 }
 
-// Run will execute all steps, halting if any step does not return layer4.Passed.
-func (a *Assessment) Run(targetData interface{}, changesAllowed bool) Result {
+// Run will execute all steps, halting if any step returns layer4.Failed unless the run continues on failure.
+// Options may put the changes in plan mode or restrict them to those approved in a plan.
+func (a *Assessment) Run(targetData interface{}, changesAllowed bool, opts ...RunOption) Result {
 	if a.Result != NotRun {
 		return a.Result
 	}
@@ -97,20 +271,74 @@ func (a *Assessment) Run(targetData interface{}, changesAllowed bool) Result {
 		a.Result = Unknown
 		return a.Result
 	}
-	for _, change := range a.Changes {
-		if changesAllowed {
+	options := newRunOpts(opts)
+	if options.redactor != nil {
+		a.setRedactor(options.redactor)
+	}
+	for name, change := range a.Changes {
+		options.authorizeChange(a.RequirementId, name, change)
+		switch {
+		case options.planOnly:
+			change.PlanOnly()
+		case options.plan != nil:
+			if _, approved := options.plan.approved(options.controlId, a.RequirementId, name); changesAllowed && approved {
+				change.Allow()
+			}
+		case changesAllowed:
 			change.Allow()
 		}
 	}
+	var failure string
 	for _, step := range a.Steps {
 		if a.runStep(targetData, step) == Failed {
-			return Failed
+			if !options.continueOnFailure {
+				return Failed
+			}
+			if failure == "" {
+				failure = a.Message
+			}
 		}
 	}
+	if failure != "" {
+		a.Message = failure
+	}
 	a.End = time.Now().Format(time.RFC3339)
 	return a.Result
 }
 
+// Reset clears the results of a previous run, including the message, step count and durations, timestamps, value,
+// evidence, waiver and attestation, so that the assessment can be run again with the same steps and changes.
+// It returns an error without resetting anything if a change applied by the previous run was not reverted.
+func (a *Assessment) Reset() error {
+	if err := a.checkReverted(); err != nil {
+		return err
+	}
+	for _, change := range a.Changes {
+		change.reset()
+	}
+	a.Result = NotRun
+	a.Message = ""
+	a.StepsExecuted = 0
+	a.StepDurations = nil
+	a.Start = ""
+	a.End = ""
+	a.Value = nil
+	a.Evidence = nil
+	a.Waiver = nil
+	a.Attestation = nil
+	return nil
+}
+
+// checkReverted returns an error if any change applied by the assessment was not reverted.
+func (a *Assessment) checkReverted() error {
+	for name, change := range a.Changes {
+		if change.Applied && !change.Reverted {
+			return fmt.Errorf("cannot reset assessment %s: change %s on %s was not reverted", a.RequirementId, name, change.TargetName)
+		}
+	}
+	return nil
+}
+
 // NewChange creates a new Change object and adds it to the Assessment.
 func (a *Assessment) NewChange(
 	changeName,
@@ -128,20 +356,11 @@ func (a *Assessment) NewChange(
 	return &change
 }
 
-// RevertChanges reverts all changes made by the assessment.
-// It will not revert changes that have not been applied.
-func (a *Assessment) RevertChanges() (corrupted bool) {
-	for _, change := range a.Changes {
-		if !corrupted && (change.Applied || change.Error != nil) {
-			if !change.Reverted {
-				change.Revert(nil)
-			}
-			if change.Error != nil || !change.Reverted {
-				corrupted = true // do not break loop here; continue attempting to revert all changes
-			}
-		}
-	}
-	return
+// RevertChanges reverts all changes made by the assessment, most recently applied first.
+// It will not revert changes that have not been applied. Every change is attempted even if an earlier one
+// could not be reverted, and options may retry failed reverts. It returns true if any target was left modified.
+func (a *Assessment) RevertChanges(opts ...RunOption) (corrupted bool) {
+	return len(revertChanges(a.namedChanges(), opts...)) > 0
 }
 
 // precheck verifies that the assessment has all the required fields.
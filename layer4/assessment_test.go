@@ -1,7 +1,10 @@
 package layer4
 
 import (
+	"errors"
+	"strings"
 	"testing"
+	"time"
 )
 
 func getAssessmentsTestData() []struct {
@@ -293,3 +296,50 @@ func TestNewAssessment(t *testing.T) {
 		})
 	}
 }
+
+func TestRevertChangesOrderAndRetries(t *testing.T) {
+	var reverted []string
+	failures := map[string]int{"create-rule": 2, "delete-repo": 10}
+	evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+	for _, requirementId := range []string{"OSPS-AC-01.01", "OSPS-AC-01.02"} {
+		evaluation.Assessments = append(evaluation.Assessments, &Assessment{RequirementId: requirementId})
+	}
+	newChange := func(assessment *Assessment, name string) *Change {
+		change := assessment.NewChange(name, name+"-target", "Change "+name, nil,
+			func(interface{}) (interface{}, error) { return nil, nil },
+			func(interface{}) error {
+				if failures[name] > 0 {
+					failures[name]--
+					return errors.New("temporary failure")
+				}
+				reverted = append(reverted, name)
+				return nil
+			},
+		)
+		change.Allow()
+		return change
+	}
+	// Changes are applied in a different order than their names and assessments
+	order := []struct {
+		assessment int
+		name       string
+	}{{1, "enable-setting"}, {0, "create-rule"}, {1, "delete-repo"}, {0, "add-member"}}
+	for _, o := range order {
+		newChange(evaluation.Assessments[o.assessment], o.name).Apply(o.name+"-target", nil, nil)
+	}
+
+	evaluation.Cleanup(WithRevertRetries(3, time.Millisecond))
+
+	expected := []string{"add-member", "create-rule", "enable-setting"}
+	if strings.Join(reverted, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected changes to be reverted in reverse order %v, got %v", expected, reverted)
+	}
+	if !evaluation.CorruptedState || len(evaluation.CorruptedChanges) != 1 {
+		t.Fatalf("expected one corrupted change, got %+v", evaluation.CorruptedChanges)
+	}
+	corrupted := evaluation.CorruptedChanges[0]
+	if corrupted.RequirementId != "OSPS-AC-01.02" || corrupted.ChangeName != "delete-repo" || corrupted.TargetName != "delete-repo-target" ||
+		corrupted.Error != "temporary failure" || corrupted.RevertAttempts != 4 {
+		t.Errorf("unexpected corruption report %+v", corrupted)
+	}
+}
@@ -0,0 +1,189 @@
+package layer4
+
+import (
+	"fmt"
+	"time"
+)
+
+// Attestation records a manual decision on an assessment that needs review, such as a reviewer confirming
+// that a requirement is met by a process that cannot be checked automatically.
+type Attestation struct {
+	// ControlId is the unique identifier of the control containing the assessment
+	ControlId string `json:"control-id" yaml:"control-id"`
+	// RequirementId is the unique identifier of the attested requirement
+	RequirementId string `json:"requirement-id" yaml:"requirement-id"`
+	// Attester is the person who made the decision
+	Attester string `json:"attester" yaml:"attester"`
+	// AttestedOn is the time the decision was made
+	AttestedOn string `json:"attested-on" yaml:"attested-on"`
+	// Evidence contains links to the evidence supporting the decision
+	Evidence []string `json:"evidence,omitempty" yaml:"evidence,omitempty"`
+	// Decision is the result decided by the attester: Passed, Failed or NotApplicable
+	Decision Result `json:"decision" yaml:"decision"`
+	// Comment is an optional explanation of the decision
+	Comment string `json:"comment,omitempty" yaml:"comment,omitempty"`
+	// Expires is the time after which the decision must be reviewed again. Attestations without an expiry do not expire.
+	Expires string `json:"expires,omitempty" yaml:"expires,omitempty"`
+}
+
+// AssessmentAttestation records the attestation that was applied to an assessment.
+type AssessmentAttestation struct {
+	Attestation `json:",inline" yaml:",inline"`
+	// AutomatedResult is the result of the assessment before the attestation was applied
+	AutomatedResult Result `json:"automated-result" yaml:"automated-result"`
+}
+
+// AttestationReport describes the outcome of applying attestations to evaluation results.
+type AttestationReport struct {
+	// Applied lists the assessments whose result was changed by an attestation
+	Applied []ResultChange `json:"applied" yaml:"applied"`
+	// Expired lists the attestations that were not applied because they have expired
+	Expired []Attestation `json:"expired" yaml:"expired"`
+	// Unused lists the attestations that did not match an assessment that needs review
+	Unused []Attestation `json:"unused" yaml:"unused"`
+}
+
+type attestationOpts struct {
+	now time.Time
+}
+
+// AttestationOption defines an option to tune the behavior of ApplyAttestations.
+type AttestationOption func(opts *attestationOpts)
+
+// WithAttestationTime is an AttestationOption that sets the time used to check attestation expiry, instead of the current time.
+func WithAttestationTime(now time.Time) AttestationOption {
+	return func(opts *attestationOpts) {
+		opts.now = now
+	}
+}
+
+// ApplyAttestations applies manual attestations to the assessments that need review. The result of each
+// matching assessment is replaced by the attestation's decision, and the automated result is kept in the
+// assessment's Attestation record. If several attestations match an assessment, the most recent one is used.
+// Control results are recalculated from their assessments. Applying attestations again replaces earlier ones,
+// along with any waiver applied to an earlier decision, so waivers should be applied again afterwards.
+//
+// To retain attestations across runs, apply the attestations of the previous run to the new results:
+//
+//	report, err := current.ApplyAttestations(previous.Attestations())
+//
+// Attestations stop applying once they expire, or once the automated result no longer needs review.
+func (e *EvaluationResults) ApplyAttestations(attestations []Attestation, opts ...AttestationOption) (AttestationReport, error) {
+	options := attestationOpts{now: time.Now()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	report := AttestationReport{Applied: []ResultChange{}, Expired: []Attestation{}, Unused: []Attestation{}}
+	latest := make(map[[2]string]int)
+	attestedOn := make([]time.Time, len(attestations))
+	expired := make(map[int]bool)
+	for i, attestation := range attestations {
+		if err := attestation.validate(); err != nil {
+			return AttestationReport{}, err
+		}
+		attestedOn[i], _ = time.Parse(time.RFC3339, attestation.AttestedOn)
+		if attestation.expired(options.now) {
+			expired[i] = true
+			report.Expired = append(report.Expired, attestation)
+			continue
+		}
+		key := [2]string{attestation.ControlId, attestation.RequirementId}
+		if previous, found := latest[key]; !found || attestedOn[i].After(attestedOn[previous]) {
+			latest[key] = i
+		}
+	}
+
+	used := make(map[int]bool)
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		changed := false
+		for _, assessment := range evaluation.Assessments {
+			if assessment == nil {
+				continue
+			}
+			if assessment.Attestation != nil {
+				assessment.Result = assessment.Attestation.AutomatedResult
+				assessment.Attestation = nil
+				// A waiver of the attested decision no longer applies once the decision is replaced
+				assessment.Waiver = nil
+				changed = true
+			}
+			if assessment.Result != NeedsReview {
+				continue
+			}
+			index, found := latest[[2]string{evaluation.ControlID, assessment.RequirementId}]
+			if !found {
+				continue
+			}
+			attestation := attestations[index]
+			used[index] = true
+			assessment.Attestation = &AssessmentAttestation{Attestation: attestation, AutomatedResult: assessment.Result}
+			assessment.Result = attestation.Decision
+			report.Applied = append(report.Applied, ResultChange{
+				ControlId:      evaluation.ControlID,
+				RequirementId:  assessment.RequirementId,
+				PreviousResult: NeedsReview,
+				Result:         attestation.Decision,
+			})
+			changed = true
+		}
+		if changed {
+			evaluation.aggregateAssessments()
+		}
+	}
+
+	for i, attestation := range attestations {
+		if !used[i] && !expired[i] {
+			report.Unused = append(report.Unused, attestation)
+		}
+	}
+	return report, nil
+}
+
+// Attestations returns the attestations that were applied to the assessments in the evaluation results,
+// so that they can be applied to a later run.
+func (e *EvaluationResults) Attestations() []Attestation {
+	var attestations []Attestation
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		for _, assessment := range evaluation.Assessments {
+			if assessment != nil && assessment.Attestation != nil {
+				attestations = append(attestations, assessment.Attestation.Attestation)
+			}
+		}
+	}
+	return attestations
+}
+
+func (a Attestation) validate() error {
+	if a.ControlId == "" || a.RequirementId == "" || a.Attester == "" {
+		return fmt.Errorf("attestation for %s %s must have a control id, requirement id and attester", a.ControlId, a.RequirementId)
+	}
+	switch a.Decision {
+	case Passed, Failed, NotApplicable:
+	default:
+		return fmt.Errorf("attestation for %s has an invalid decision %q; expected Passed, Failed or Not Applicable", a.RequirementId, a.Decision)
+	}
+	if _, err := time.Parse(time.RFC3339, a.AttestedOn); err != nil {
+		return fmt.Errorf("attestation for %s has an invalid attestation time: %w", a.RequirementId, err)
+	}
+	if a.Expires != "" {
+		if _, err := time.Parse(time.RFC3339, a.Expires); err != nil {
+			return fmt.Errorf("attestation for %s has an invalid expiry: %w", a.RequirementId, err)
+		}
+	}
+	return nil
+}
+
+func (a Attestation) expired(now time.Time) bool {
+	if a.Expires == "" {
+		return false
+	}
+	expires, _ := time.Parse(time.RFC3339, a.Expires)
+	return !expires.After(now)
+}
@@ -0,0 +1,132 @@
+package layer4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+)
+
+func attestationResults() *EvaluationResults {
+	return &EvaluationResults{
+		EvaluationSet: []*ControlEvaluation{
+			{
+				ControlID: "OSPS-GV-02",
+				Result:    NeedsReview,
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-GV-02.01", Result: NeedsReview, Message: "Discussion forum could not be verified"},
+					{RequirementId: "OSPS-GV-02.02", Result: Passed},
+				},
+			},
+			{
+				ControlID: "OSPS-DO-01",
+				Result:    NeedsReview,
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-DO-01.01", Result: NeedsReview},
+				},
+			},
+		},
+	}
+}
+
+func TestApplyAttestations(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	attestations := []Attestation{
+		{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "alice", AttestedOn: "2025-05-01T00:00:00Z", Decision: Failed},
+		{
+			ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "bob", AttestedOn: "2025-05-02T00:00:00Z",
+			Evidence: []string{"https://github.com/ossf/gemara/discussions"}, Decision: Passed, Expires: "2025-12-01T00:00:00Z",
+		},
+		{ControlId: "OSPS-DO-01", RequirementId: "OSPS-DO-01.01", Attester: "carol", AttestedOn: "2025-01-01T00:00:00Z", Decision: Passed, Expires: "2025-02-01T00:00:00Z"},
+		{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.02", Attester: "dave", AttestedOn: "2025-05-01T00:00:00Z", Decision: Failed},
+	}
+
+	results := attestationResults()
+	report, err := results.ApplyAttestations(attestations, WithAttestationTime(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attested := results.EvaluationSet[0].Assessments[0]
+	if attested.Result != Passed || attested.Attestation == nil || attested.Attestation.Attester != "bob" || attested.Attestation.AutomatedResult != NeedsReview {
+		t.Errorf("expected the most recent attestation to pass the assessment, got %s with %+v", attested.Result, attested.Attestation)
+	}
+	if results.EvaluationSet[0].Result != Passed {
+		t.Errorf("expected the control result to be recalculated, got %s", results.EvaluationSet[0].Result)
+	}
+	if results.EvaluationSet[1].Assessments[0].Result != NeedsReview {
+		t.Error("expected the expired attestation not to be applied")
+	}
+	if results.EvaluationSet[0].Assessments[1].Attestation != nil {
+		t.Error("expected attestations not to apply to assessments that do not need review")
+	}
+	if len(report.Applied) != 1 || len(report.Expired) != 1 || len(report.Unused) != 2 {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	// The attestation is retained in the next run until it expires
+	next := attestationResults()
+	if _, err := next.ApplyAttestations(results.Attestations(), WithAttestationTime(now.AddDate(0, 1, 0))); err != nil {
+		t.Fatal(err)
+	}
+	if next.EvaluationSet[0].Assessments[0].Result != Passed {
+		t.Errorf("expected the attestation to carry forward, got %s", next.EvaluationSet[0].Assessments[0].Result)
+	}
+	report, err = next.ApplyAttestations(results.Attestations(), WithAttestationTime(now.AddDate(1, 0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.EvaluationSet[0].Assessments[0].Result != NeedsReview || len(report.Expired) != 1 {
+		t.Errorf("expected the attestation to expire and restore the automated result, got %s", next.EvaluationSet[0].Assessments[0].Result)
+	}
+}
+
+func TestApplyAttestationsInvalid(t *testing.T) {
+	tests := []struct {
+		name        string
+		attestation Attestation
+	}{
+		{
+			name:        "missing attester",
+			attestation: Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", AttestedOn: "2025-05-01T00:00:00Z", Decision: Passed},
+		},
+		{
+			name:        "invalid decision",
+			attestation: Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "alice", AttestedOn: "2025-05-01T00:00:00Z", Decision: NeedsReview},
+		},
+		{
+			name:        "invalid expiry",
+			attestation: Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "alice", AttestedOn: "2025-05-01T00:00:00Z", Decision: Passed, Expires: "soon"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := attestationResults().ApplyAttestations([]Attestation{test.attestation}); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestAttestationRoundTrip(t *testing.T) {
+	results := attestationResults()
+	attestation := Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "alice", AttestedOn: "2025-05-01T00:00:00Z", Decision: Passed}
+	if _, err := results.ApplyAttestations([]Attestation{attestation}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := yaml.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &EvaluationResults{}
+	if err := yaml.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	attestations := loaded.Attestations()
+	if len(attestations) != 1 || attestations[0].Attester != "alice" || attestations[0].Decision != Passed {
+		t.Errorf("expected the attestation to round trip, got %+v", attestations)
+	}
+	if loaded.EvaluationSet[0].Assessments[0].Attestation.AutomatedResult != NeedsReview {
+		t.Error("expected the automated result to round trip")
+	}
+}
@@ -0,0 +1,133 @@
+package layer4
+
+import (
+	"fmt"
+	"path"
+)
+
+// ChangeRequest describes a change that an assessment step is about to apply, for authorization.
+type ChangeRequest struct {
+	// ControlId is the unique identifier of the control being evaluated, if the change is applied during a control evaluation
+	ControlId string
+	// RequirementId is the unique identifier of the assessment applying the change
+	RequirementId string
+	// ChangeName is the name of the change within the assessment
+	ChangeName string
+	// TargetName is the name or ID of the resource that would be changed
+	TargetName string
+	// Description is a human-readable description of the change
+	Description string
+	// TargetObject is supplemental data describing the object that would be changed
+	TargetObject interface{}
+	// Environment is the environment the evaluation runs against, as set with WithEnvironment
+	Environment string
+}
+
+// ChangeAuthorizer decides whether individual changes may be applied.
+// When a change is denied, the reason is recorded on the change in the results.
+type ChangeAuthorizer interface {
+	Authorize(request ChangeRequest) (allowed bool, reason string)
+}
+
+// ChangeAuthorizerFunc is a function that implements ChangeAuthorizer.
+type ChangeAuthorizerFunc func(request ChangeRequest) (allowed bool, reason string)
+
+// Authorize calls the function.
+func (f ChangeAuthorizerFunc) Authorize(request ChangeRequest) (bool, string) {
+	return f(request)
+}
+
+// ChangeRule allows or denies the changes it matches. Empty fields match any value, and patterns use path.Match syntax.
+type ChangeRule struct {
+	// Environment is the pattern matched against the environment of the evaluation
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+	// ControlId is the pattern matched against the ID of the evaluated control
+	ControlId string `json:"control-id,omitempty" yaml:"control-id,omitempty"`
+	// Target is the pattern matched against the target name of the change
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+	// Allow is true if matching changes may be applied
+	Allow bool `json:"allow" yaml:"allow"`
+	// Reason explains the rule, and is recorded when it denies a change
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// ChangePolicy is a ChangeAuthorizer that applies the first matching rule to each change.
+// Changes that match no rule are denied.
+type ChangePolicy struct {
+	Rules []ChangeRule `json:"rules" yaml:"rules"`
+}
+
+// Authorize applies the first rule matching the request.
+func (p ChangePolicy) Authorize(request ChangeRequest) (bool, string) {
+	for _, rule := range p.Rules {
+		if matchPattern(rule.Environment, request.Environment) &&
+			matchPattern(rule.ControlId, request.ControlId) &&
+			matchPattern(rule.Target, request.TargetName) {
+			return rule.Allow, rule.Reason
+		}
+	}
+	return false, "no change policy rule matched"
+}
+
+func matchPattern(pattern, value string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, value)
+	return err == nil && matched
+}
+
+// WithChangeAuthorizer is a RunOption that consults the authorizer before each change is applied.
+// Changes must still be allowed for the run; the authorizer can only deny changes that would otherwise be applied.
+func WithChangeAuthorizer(authorizer ChangeAuthorizer) RunOption {
+	return func(opts *runOpts) {
+		opts.authorizer = authorizer
+	}
+}
+
+// WithEnvironment is a RunOption that sets the environment passed to the change authorizer, such as "test" or "production".
+func WithEnvironment(environment string) RunOption {
+	return func(opts *runOpts) {
+		opts.environment = environment
+	}
+}
+
+// withControlId is a RunOption that sets the control ID passed to the change authorizer.
+func withControlId(controlId string) RunOption {
+	return func(opts *runOpts) {
+		opts.controlId = controlId
+	}
+}
+
+// authorizeChange sets the function the change uses to check its authorization when it is applied.
+// With an approved plan, the change is denied on any target other than the one it was approved for.
+func (o runOpts) authorizeChange(requirementId, changeName string, change *Change) {
+	approved, inPlan := o.plan.approved(o.controlId, requirementId, changeName)
+	if o.authorizer == nil && !inPlan {
+		return
+	}
+	change.authorize = func(targetName string, targetObject interface{}) string {
+		if inPlan && targetName != approved.TargetName {
+			return fmt.Sprintf("change %s was approved for %s, not %s", changeName, approved.TargetName, targetName)
+		}
+		if o.authorizer == nil {
+			return ""
+		}
+		allowed, reason := o.authorizer.Authorize(ChangeRequest{
+			ControlId:     o.controlId,
+			RequirementId: requirementId,
+			ChangeName:    changeName,
+			TargetName:    targetName,
+			Description:   change.Description,
+			TargetObject:  targetObject,
+			Environment:   o.environment,
+		})
+		if allowed {
+			return ""
+		}
+		if reason == "" {
+			reason = fmt.Sprintf("change %s on %s was denied", changeName, targetName)
+		}
+		return reason
+	}
+}
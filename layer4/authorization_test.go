@@ -0,0 +1,90 @@
+package layer4
+
+import (
+	"testing"
+)
+
+func TestChangeAuthorizer(t *testing.T) {
+	policy := ChangePolicy{Rules: []ChangeRule{
+		{Environment: "production", Allow: false, Reason: "changes are not allowed in production"},
+		{Target: "test-*", Allow: true},
+	}}
+
+	tests := []struct {
+		testName    string
+		environment string
+		target      string
+		applied     bool
+		denial      string
+	}{
+		{testName: "Test repository", environment: "staging", target: "test-repo", applied: true},
+		{testName: "Production organization", environment: "production", target: "test-repo", denial: "changes are not allowed in production"},
+		{testName: "No matching rule", environment: "staging", target: "ossf", denial: "no change policy rule matched"},
+	}
+	for _, test := range tests {
+		t.Run(test.testName, func(t *testing.T) {
+			var requests []ChangeRequest
+			authorizer := ChangeAuthorizerFunc(func(request ChangeRequest) (bool, string) {
+				requests = append(requests, request)
+				return policy.Authorize(request)
+			})
+			evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+			assessment := evaluation.AddAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []AssessmentStep{
+				func(data interface{}, changes map[string]*Change) (Result, string) {
+					changes["enable-mfa"].Apply(test.target, nil, nil)
+					return Passed, "MFA is required"
+				},
+			})
+			change := assessment.NewChange("enable-mfa", "ossf", "Change enable-mfa", nil,
+				func(interface{}) (interface{}, error) { return nil, nil },
+				func(interface{}) error { return nil },
+			)
+
+			evaluation.Evaluate(nil, []string{"Maturity Level 1"}, true, WithChangeAuthorizer(authorizer), WithEnvironment(test.environment))
+
+			if change.Applied != test.applied || change.Denial != test.denial {
+				t.Errorf("expected applied=%v with denial %q, got applied=%v with denial %q", test.applied, test.denial, change.Applied, change.Denial)
+			}
+			if len(requests) != 1 {
+				t.Fatalf("expected the authorizer to be consulted once, got %d", len(requests))
+			}
+			request := requests[0]
+			if request.ControlId != "OSPS-AC-01" || request.RequirementId != "OSPS-AC-01.01" || request.ChangeName != "enable-mfa" ||
+				request.TargetName != test.target || request.Description != "Change enable-mfa" || request.Environment != test.environment {
+				t.Errorf("unexpected change request %+v", request)
+			}
+		})
+	}
+}
+
+func TestChangeAuthorizerRequiresChangesAllowed(t *testing.T) {
+	target := &planTarget{}
+	evaluation := target.evaluation()
+	allowAll := ChangeAuthorizerFunc(func(ChangeRequest) (bool, string) { return true, "" })
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, false, WithChangeAuthorizer(allowAll))
+	if len(target.applied) != 0 {
+		t.Errorf("expected no changes to be applied when changes are not allowed, got %v", target.applied)
+	}
+}
+
+func TestChangeAuthorizerClearsDenial(t *testing.T) {
+	evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+	assessment := evaluation.AddAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []AssessmentStep{
+		func(data interface{}, changes map[string]*Change) (Result, string) {
+			changes["enable-mfa"].Apply("ossf", nil, nil)
+			changes["enable-mfa"].Apply("test-repo", nil, nil)
+			return Passed, "MFA is required"
+		},
+	})
+	change := assessment.NewChange("enable-mfa", "ossf", "Change enable-mfa", nil,
+		func(interface{}) (interface{}, error) { return nil, nil },
+		func(interface{}) error { return nil },
+	)
+	policy := ChangePolicy{Rules: []ChangeRule{{Target: "test-*", Allow: true}}}
+
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, true, WithChangeAuthorizer(policy))
+
+	if !change.Applied || change.Denial != "" {
+		t.Errorf("expected the change to be applied without a denial, got applied=%v with denial %q", change.Applied, change.Denial)
+	}
+}
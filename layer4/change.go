@@ -1,7 +1,12 @@
 package layer4
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
+
+	"github.com/goccy/go-yaml"
 )
 
 // Prepared function to apply the change
@@ -13,23 +18,194 @@ type RevertFunc func(interface{}) error
 // Change is a struct that contains the data and functions associated with a single change to a target resource.
 type Change struct {
 	// TargetName is the name or ID of the resource or configuration that is to be changed
-	TargetName string `yaml:"target-name"`
+	TargetName string `json:"target-name" yaml:"target-name"`
 	// Description is a human-readable description of the change
-	Description string `yaml:"description"`
+	Description string `json:"description" yaml:"description"`
 	// applyFunc is the function that will be executed to make the change
 	applyFunc ApplyFunc
 	// revertFunc is the function that will be executed to undo the change
 	revertFunc RevertFunc
 	// TargetObject is supplemental data describing the object that was changed
-	TargetObject interface{} `yaml:"target-object,omitempty"`
+	TargetObject interface{} `json:"target-object,omitempty" yaml:"target-object,omitempty"`
 	// Applied is true if the change was successfully applied at least once
-	Applied bool `yaml:"applied,omitempty"`
+	Applied bool `json:"applied,omitempty" yaml:"applied,omitempty"`
 	// Reverted is true if the change was successfully reverted and not applied again
-	Reverted bool `yaml:"reverted,omitempty"`
+	Reverted bool `json:"reverted,omitempty" yaml:"reverted,omitempty"`
 	// Error is used if any error occurred during the change
-	Error error `yaml:"error,omitempty"`
+	Error error `json:"error,omitempty" yaml:"error,omitempty"`
 	// Allowed may be disabled to prevent the change from being applied
-	Allowed bool `yaml:"allowed,omitempty"`
+	Allowed bool `json:"allowed,omitempty" yaml:"allowed,omitempty"`
+	// Planned is true if the change would have been applied during a run in plan mode
+	Planned bool `json:"planned,omitempty" yaml:"planned,omitempty"`
+	// Denial is the reason a change authorizer denied the change, if it was denied
+	Denial string `json:"denial,omitempty" yaml:"denial,omitempty"`
+	// AppliedAt is the time the change was last applied successfully
+	AppliedAt string `json:"applied-at,omitempty" yaml:"applied-at,omitempty"`
+	// RevertedAt is the time the change was last reverted successfully
+	RevertedAt string `json:"reverted-at,omitempty" yaml:"reverted-at,omitempty"`
+	// ApplyAttempts is the number of times the apply function was executed
+	ApplyAttempts int `json:"apply-attempts,omitempty" yaml:"apply-attempts,omitempty"`
+	// RevertAttempts is the number of times the revert function was executed
+	RevertAttempts int `json:"revert-attempts,omitempty" yaml:"revert-attempts,omitempty"`
+
+	// planOnly records changes as planned instead of applying them
+	planOnly bool
+	// readOnly is true if the change was loaded from serialized results
+	readOnly bool
+	// sequence orders the change by the time it was last applied, so that changes are reverted in reverse order
+	sequence uint64
+	// authorize returns the reason the change is denied on the target, or an empty string if it is authorized
+	authorize func(targetName string, targetObject interface{}) string
+	// redactor removes secrets from the change when it is serialized
+	redactor *Redactor
+
+	// journal records the change before it is applied, if enabled
+	journal *Journal
+	// journalEntry is the entry recorded in the journal for the current application of the change
+	journalEntry JournalEntry
+}
+
+// changeRecord is the serialized form of a Change, with its error recorded as a string.
+type changeRecord struct {
+	TargetName     string      `json:"target-name" yaml:"target-name"`
+	Description    string      `json:"description" yaml:"description"`
+	TargetObject   interface{} `json:"target-object,omitempty" yaml:"target-object,omitempty"`
+	Applied        bool        `json:"applied,omitempty" yaml:"applied,omitempty"`
+	Reverted       bool        `json:"reverted,omitempty" yaml:"reverted,omitempty"`
+	Error          string      `json:"error,omitempty" yaml:"error,omitempty"`
+	Allowed        bool        `json:"allowed,omitempty" yaml:"allowed,omitempty"`
+	Planned        bool        `json:"planned,omitempty" yaml:"planned,omitempty"`
+	Denial         string      `json:"denial,omitempty" yaml:"denial,omitempty"`
+	AppliedAt      string      `json:"applied-at,omitempty" yaml:"applied-at,omitempty"`
+	RevertedAt     string      `json:"reverted-at,omitempty" yaml:"reverted-at,omitempty"`
+	ApplyAttempts  int         `json:"apply-attempts,omitempty" yaml:"apply-attempts,omitempty"`
+	RevertAttempts int         `json:"revert-attempts,omitempty" yaml:"revert-attempts,omitempty"`
+}
+
+// redactedRecord returns the serialized form of the change in the format, "json" or "yaml",
+// with its Redactor applied.
+func (c *Change) redactedRecord(format string) changeRecord {
+	redactor := c.redactor
+	record := changeRecord{
+		TargetName:     redactor.RedactString("target-name", c.TargetName),
+		Description:    redactor.RedactString("description", c.Description),
+		TargetObject:   redactor.redactValue(format, "target-object", c.TargetObject),
+		Applied:        c.Applied,
+		Reverted:       c.Reverted,
+		Allowed:        c.Allowed,
+		Planned:        c.Planned,
+		Denial:         redactor.RedactString("denial", c.Denial),
+		AppliedAt:      c.AppliedAt,
+		RevertedAt:     c.RevertedAt,
+		ApplyAttempts:  c.ApplyAttempts,
+		RevertAttempts: c.RevertAttempts,
+	}
+	if c.Error != nil {
+		record.Error = redactor.RedactString("error", c.Error.Error())
+	}
+	return record
+}
+
+// fromRecord restores a change from its serialized form as a read-only record.
+func (c *Change) fromRecord(record changeRecord) {
+	*c = Change{
+		TargetName:     record.TargetName,
+		Description:    record.Description,
+		TargetObject:   record.TargetObject,
+		Applied:        record.Applied,
+		Reverted:       record.Reverted,
+		Allowed:        record.Allowed,
+		Planned:        record.Planned,
+		Denial:         record.Denial,
+		AppliedAt:      record.AppliedAt,
+		RevertedAt:     record.RevertedAt,
+		ApplyAttempts:  record.ApplyAttempts,
+		RevertAttempts: record.RevertAttempts,
+		readOnly:       true,
+	}
+	if record.Error != "" {
+		c.Error = errors.New(record.Error)
+	}
+}
+
+// MarshalYAML serializes the change with its error as a string and secrets redacted
+func (c *Change) MarshalYAML() (interface{}, error) {
+	return c.redactedRecord("yaml"), nil
+}
+
+// UnmarshalYAML deserializes a change written by MarshalYAML.
+// Apply and revert functions cannot be restored, so the loaded change is a read-only record of what happened.
+func (c *Change) UnmarshalYAML(data []byte) error {
+	var record changeRecord
+	if err := yaml.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	c.fromRecord(record)
+	return nil
+}
+
+// MarshalJSON serializes the change with its error as a string and secrets redacted
+func (c *Change) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.redactedRecord("json"))
+}
+
+// UnmarshalJSON deserializes a change written by MarshalJSON.
+// Apply and revert functions cannot be restored, so the loaded change is a read-only record of what happened.
+func (c *Change) UnmarshalJSON(data []byte) error {
+	var record changeRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return err
+	}
+	c.fromRecord(record)
+	return nil
+}
+
+// ReadOnly returns true if the change was loaded from serialized results, in which case it cannot be applied or reverted.
+func (c *Change) ReadOnly() bool {
+	return c.readOnly
+}
+
+// EnableJournal records the change in the journal before it is applied, so that the revert handler registered
+// with the given name can revert it if the process exits before the change is reverted. The revert input is
+// serialized as JSON and passed to the handler during recovery. Revert handlers should be idempotent, as a change
+// may be reverted again if the process exits before its revert is recorded.
+func (c *Change) EnableJournal(journal *Journal, handler string, revertInput interface{}) error {
+	input, err := json.Marshal(revertInput)
+	if err != nil {
+		return fmt.Errorf("revert input for change on %s cannot be journaled: %w", c.TargetName, err)
+	}
+	c.journal = journal
+	c.journalEntry = JournalEntry{Handler: handler, RevertInput: input}
+	return nil
+}
+
+// writeJournal records an event for the change if it is journaled.
+func (c *Change) writeJournal(event JournalEvent) error {
+	if c.journal == nil {
+		return nil
+	}
+	entry := c.journalEntry
+	entry.Event = event
+	entry.TargetName = c.TargetName
+	entry.Description = c.Description
+	return c.journal.write(entry)
+}
+
+// reset clears the state recorded by a previous run, keeping the configuration of the change.
+func (c *Change) reset() {
+	c.Applied = false
+	c.Reverted = false
+	c.Error = nil
+	c.Allowed = false
+	c.Planned = false
+	c.Denial = ""
+	c.AppliedAt = ""
+	c.RevertedAt = ""
+	c.ApplyAttempts = 0
+	c.RevertAttempts = 0
+	c.planOnly = false
+	c.authorize = nil
+	c.sequence = 0
 }
 
 // Allow marks the change as allowed to be applied.
@@ -37,10 +213,17 @@ func (c *Change) Allow() {
 	c.Allowed = true
 }
 
+// PlanOnly puts the change in plan mode, in which Apply records what would be changed instead of applying the change.
+func (c *Change) PlanOnly() {
+	c.planOnly = true
+}
+
 // Apply the prepared function for the change. It will not apply the change if it has already been applied and not reverted.
-// It will also not apply the change if it is not allowed.
+// It will also not apply the change if it is not allowed, or if the change authorizer of the run denies it, in which case
+// the reason is recorded until the change is next authorized. In plan mode, the target is recorded and the change is marked as planned without being applied,
+// whether or not it is allowed.
 func (c *Change) Apply(targetName string, targetObject interface{}, changeInput interface{}) (applied bool, changeOutput interface{}) {
-	if !c.Allowed {
+	if c.readOnly || (!c.Allowed && !c.planOnly) {
 		return
 	}
 	err := c.precheck()
@@ -48,23 +231,55 @@ func (c *Change) Apply(targetName string, targetObject interface{}, changeInput
 		c.Error = err
 		return
 	}
+	if c.authorize != nil {
+		if denial := c.authorize(targetName, targetObject); denial != "" {
+			c.TargetName = targetName
+			c.TargetObject = targetObject
+			c.Denial = denial
+			return
+		}
+	}
+	c.Denial = ""
+	if c.planOnly {
+		c.TargetName = targetName
+		c.TargetObject = targetObject
+		c.Planned = true
+		return
+	}
 	// Do nothing if the change has already been applied and not reverted
 	if c.Applied && !c.Reverted {
 		return true, nil
 	}
 	c.TargetName = targetName
 	c.TargetObject = targetObject
+	c.journalEntry.ChangeId = newChangeId()
+	if err := c.writeJournal(JournalApplying); err != nil {
+		c.Error = err
+		return
+	}
+	c.ApplyAttempts++
 	changeOutput, err = c.applyFunc(changeInput)
+	// Failures to record the outcome are ignored, as a change left applying in the journal is still reverted by Recover
 	if err != nil {
+		_ = c.writeJournal(JournalApplyFailed)
 		return false, changeOutput
 	}
+	_ = c.writeJournal(JournalApplied)
+	c.AppliedAt = time.Now().Format(time.RFC3339)
+	c.sequence = changeSequence.Add(1)
 	c.Applied = true
 	c.Reverted = false
 	return true, changeOutput
 }
 
 // Revert the change by executing the revert function. It will not revert the change if it has not been applied.
+// Changes loaded from serialized results are read-only and are neither applied nor reverted.
+// If the revert cannot be recorded in the journal, the change is reverted but keeps the error, as the journal
+// still lists it as pending and Journal.Recover would revert it again.
 func (c *Change) Revert(data interface{}) {
+	if c.readOnly {
+		return
+	}
 	err := c.precheck()
 	if err != nil {
 		c.Error = err
@@ -73,12 +288,17 @@ func (c *Change) Revert(data interface{}) {
 	if !c.Applied {
 		return
 	}
+	c.RevertAttempts++
 	err = c.revertFunc(data)
 	if err != nil {
 		c.Error = err
 		return
 	}
+	c.RevertedAt = time.Now().Format(time.RFC3339)
 	c.Reverted = true
+	if err := c.writeJournal(JournalReverted); err != nil {
+		c.Error = fmt.Errorf("change was reverted but could not be recorded as reverted: %w", err)
+	}
 }
 
 // precheck verifies that the applyFunc and revertFunc are defined for the change.
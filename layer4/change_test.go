@@ -1,6 +1,12 @@
 package layer4
 
-import "testing"
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
 
 func changesTestData() []struct {
 	testName string
@@ -133,3 +139,65 @@ func TestRevert(t *testing.T) {
 		})
 	}
 }
+
+func TestChangeRoundTrip(t *testing.T) {
+	attempts := 0
+	change := NewChange("repo", "Enable branch protection", map[string]interface{}{"branch": "main"},
+		func(interface{}) (interface{}, error) { return nil, nil },
+		func(interface{}) error {
+			attempts++
+			if attempts == 1 {
+				return nil
+			}
+			return errors.New("permission denied")
+		},
+	)
+	change.Allow()
+	change.Apply("repo", map[string]interface{}{"branch": "main"}, nil)
+	change.Revert(nil)
+	change.Apply("repo", map[string]interface{}{"branch": "main"}, nil)
+	change.Revert(nil)
+
+	if change.ApplyAttempts != 2 || change.RevertAttempts != 2 || change.AppliedAt == "" || change.RevertedAt == "" {
+		t.Fatalf("expected attempts and timestamps to be recorded, got %+v", change)
+	}
+
+	marshalers := map[string]struct {
+		marshal   func(interface{}) ([]byte, error)
+		unmarshal func([]byte, interface{}) error
+	}{
+		"YAML": {yaml.Marshal, yaml.Unmarshal},
+		"JSON": {json.Marshal, json.Unmarshal},
+	}
+	for format, m := range marshalers {
+		t.Run(format, func(t *testing.T) {
+			data, err := m.marshal(&change)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var loaded Change
+			if err := m.unmarshal(data, &loaded); err != nil {
+				t.Fatalf("error loading change: %v\n%s", err, data)
+			}
+			if loaded.Error == nil || loaded.Error.Error() != "permission denied" {
+				t.Errorf("expected error to survive a round trip, got %v", loaded.Error)
+			}
+			if loaded.TargetName != "repo" || !loaded.Applied || loaded.Reverted || loaded.AppliedAt != change.AppliedAt ||
+				loaded.RevertedAt != change.RevertedAt || loaded.ApplyAttempts != 2 || loaded.RevertAttempts != 2 {
+				t.Errorf("expected change to survive a round trip, got %+v", loaded)
+			}
+			if !loaded.ReadOnly() {
+				t.Error("expected loaded change to be read-only")
+			}
+
+			loaded.Allow()
+			if applied, _ := loaded.Apply("repo", nil, nil); applied {
+				t.Error("expected a read-only change not to be applied")
+			}
+			loaded.Revert(nil)
+			if loaded.Error.Error() != "permission denied" || loaded.RevertAttempts != 2 {
+				t.Errorf("expected a read-only change not to be modified, got %+v", loaded)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package layer4
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResultChange records the result and message of a control evaluation or assessment in two evaluation runs.
+type ResultChange struct {
+	// ControlId is the unique identifier of the evaluated control
+	ControlId string `json:"control-id" yaml:"control-id"`
+	// RequirementId is the unique identifier of the assessed requirement, or empty for a control evaluation
+	RequirementId string `json:"requirement-id,omitempty" yaml:"requirement-id,omitempty"`
+	// PreviousResult is the result in the previous run, or NotRun if the item was not present
+	PreviousResult Result `json:"previous-result" yaml:"previous-result"`
+	// Result is the result in the current run, or NotRun if the item is no longer present
+	Result Result `json:"result" yaml:"result"`
+	// PreviousMessage is the message in the previous run
+	PreviousMessage string `json:"previous-message,omitempty" yaml:"previous-message,omitempty"`
+	// Message is the message in the current run
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+
+	// redactor removes secrets from the messages when the change is serialized
+	redactor *Redactor
+}
+
+// resultChangeFields is the serialized form of a ResultChange.
+type resultChangeFields ResultChange
+
+func (r ResultChange) redacted() resultChangeFields {
+	fields := resultChangeFields(r)
+	fields.PreviousMessage = r.RedactedPreviousMessage()
+	fields.Message = r.RedactedMessage()
+	return fields
+}
+
+// RedactedPreviousMessage returns the previous message with secrets redacted.
+func (r ResultChange) RedactedPreviousMessage() string {
+	return r.redactor.RedactString("previous-message", r.PreviousMessage)
+}
+
+// RedactedMessage returns the message with secrets redacted.
+func (r ResultChange) RedactedMessage() string {
+	return r.redactor.RedactString("message", r.Message)
+}
+
+// MarshalYAML serializes the change with secrets redacted from its messages
+func (r ResultChange) MarshalYAML() (interface{}, error) {
+	return r.redacted(), nil
+}
+
+// MarshalJSON serializes the change with secrets redacted from its messages
+func (r ResultChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.redacted())
+}
+
+// Comparison contains the differences between two evaluation runs.
+// Control evaluations are matched by control ID and assessments by requirement ID.
+type Comparison struct {
+	// Changed lists all controls and requirements whose result changed
+	Changed []ResultChange `json:"changed,omitempty" yaml:"changed,omitempty"`
+	// NewlyFailing lists the controls and requirements that failed in the current run but not in the previous run
+	NewlyFailing []ResultChange `json:"newly-failing,omitempty" yaml:"newly-failing,omitempty"`
+	// NewlyPassing lists the controls and requirements that passed in the current run but not in the previous run
+	NewlyPassing []ResultChange `json:"newly-passing,omitempty" yaml:"newly-passing,omitempty"`
+	// MessageChanged lists the controls and requirements whose result is unchanged but whose message changed
+	MessageChanged []ResultChange `json:"message-changed,omitempty" yaml:"message-changed,omitempty"`
+}
+
+// IsEmpty returns true if the results and messages of both runs are identical.
+func (c Comparison) IsEmpty() bool {
+	return len(c.Changed) == 0 && len(c.MessageChanged) == 0
+}
+
+// HasRegressions returns true if any control or requirement is newly failing.
+func (c Comparison) HasRegressions() bool {
+	return len(c.NewlyFailing) > 0
+}
+
+// OtherChanges returns the result changes that are neither newly failing nor newly passing,
+// such as a requirement that now needs review.
+func (c Comparison) OtherChanges() []ResultChange {
+	var changes []ResultChange
+	for _, change := range c.Changed {
+		if change.Result != Failed && change.Result != Passed {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Summary returns a single line describing the number of changes, suitable for a notification title.
+func (c Comparison) Summary() string {
+	if c.IsEmpty() {
+		return "No changes between evaluation runs"
+	}
+	var parts []string
+	for _, count := range []struct {
+		n      int
+		label  string
+		plural string
+	}{
+		{len(c.NewlyFailing), "newly failing", "newly failing"},
+		{len(c.NewlyPassing), "newly passing", "newly passing"},
+		{len(c.Changed), "result change", "result changes"},
+		{len(c.MessageChanged), "message change", "message changes"},
+	} {
+		switch {
+		case count.n == 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.label))
+		case count.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.plural))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Compare returns the differences between a previous and a current evaluation run.
+// Controls and requirements that are only present in one of the runs are treated as NotRun in the other.
+func Compare(previous, current *EvaluationResults) Comparison {
+	var comparison Comparison
+	add := func(change ResultChange) {
+		if change.PreviousResult == change.Result {
+			if strings.TrimSpace(change.PreviousMessage) != strings.TrimSpace(change.Message) {
+				comparison.MessageChanged = append(comparison.MessageChanged, change)
+			}
+			return
+		}
+		comparison.Changed = append(comparison.Changed, change)
+		switch change.Result {
+		case Failed:
+			comparison.NewlyFailing = append(comparison.NewlyFailing, change)
+		case Passed:
+			comparison.NewlyPassing = append(comparison.NewlyPassing, change)
+		}
+	}
+
+	previousControls := indexEvaluations(previous)
+	currentControls := indexEvaluations(current)
+	for _, controlId := range unionKeys(previousControls, currentControls) {
+		previousControl := previousControls[controlId]
+		currentControl := currentControls[controlId]
+		add(ResultChange{
+			ControlId:       controlId,
+			PreviousResult:  previousControl.Result,
+			Result:          currentControl.Result,
+			PreviousMessage: previousControl.Message,
+			Message:         currentControl.Message,
+			redactor:        firstRedactor(currentControl.redactor, previousControl.redactor),
+		})
+
+		previousAssessments := indexAssessments(previousControl)
+		currentAssessments := indexAssessments(currentControl)
+		for _, requirementId := range unionKeys(previousAssessments, currentAssessments) {
+			previousAssessment := previousAssessments[requirementId]
+			currentAssessment := currentAssessments[requirementId]
+			add(ResultChange{
+				ControlId:       controlId,
+				RequirementId:   requirementId,
+				PreviousResult:  previousAssessment.Result,
+				Result:          currentAssessment.Result,
+				PreviousMessage: previousAssessment.Message,
+				Message:         currentAssessment.Message,
+				redactor:        firstRedactor(currentAssessment.redactor, previousAssessment.redactor),
+			})
+		}
+	}
+	return comparison
+}
+
+func indexEvaluations(results *EvaluationResults) map[string]ControlEvaluation {
+	evaluations := make(map[string]ControlEvaluation)
+	if results == nil {
+		return evaluations
+	}
+	for _, evaluation := range results.EvaluationSet {
+		if evaluation != nil {
+			evaluations[evaluation.ControlID] = *evaluation
+		}
+	}
+	return evaluations
+}
+
+func indexAssessments(evaluation ControlEvaluation) map[string]Assessment {
+	assessments := make(map[string]Assessment)
+	for _, assessment := range evaluation.Assessments {
+		if assessment != nil {
+			assessments[assessment.RequirementId] = *assessment
+		}
+	}
+	return assessments
+}
+
+// unionKeys returns the sorted keys of both maps.
+func unionKeys[T any](a, b map[string]T) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, found := a[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
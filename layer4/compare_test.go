@@ -0,0 +1,137 @@
+package layer4
+
+import (
+	"testing"
+)
+
+func loadComparisonResults(t *testing.T) *EvaluationResults {
+	results := &EvaluationResults{}
+	if err := results.LoadFile("./test-data/pvtr-baseline-scan.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	return results
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name            string
+		update          func(r *EvaluationResults)
+		wantChanged     int
+		wantFailing     []string
+		wantPassing     []string
+		wantMessages    int
+		wantSummary     string
+		wantRegressions bool
+	}{
+		{
+			name:        "No changes",
+			update:      func(r *EvaluationResults) {},
+			wantSummary: "No changes between evaluation runs",
+		},
+		{
+			name: "Requirement newly failing",
+			update: func(r *EvaluationResults) {
+				r.EvaluationSet[0].Result = Failed
+				r.EvaluationSet[0].Assessments[0].Result = Failed
+			},
+			wantChanged:     2,
+			wantFailing:     []string{"OSPS-AC-01", "OSPS-AC-01.01"},
+			wantSummary:     "2 newly failing, 2 result changes",
+			wantRegressions: true,
+		},
+		{
+			name: "Message changed",
+			update: func(r *EvaluationResults) {
+				r.EvaluationSet[1].Assessments[0].Message = "Enforced by GitHub"
+			},
+			wantMessages: 1,
+			wantSummary:  "1 message change",
+		},
+		{
+			name: "Control removed",
+			update: func(r *EvaluationResults) {
+				r.EvaluationSet = r.EvaluationSet[1:]
+			},
+			wantChanged: 2,
+			wantSummary: "2 result changes",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous := loadComparisonResults(t)
+			current := loadComparisonResults(t)
+			tt.update(current)
+
+			comparison := Compare(previous, current)
+			if len(comparison.Changed) != tt.wantChanged {
+				t.Errorf("expected %d changed results, got %d", tt.wantChanged, len(comparison.Changed))
+			}
+			if ids := changeIds(comparison.NewlyFailing); !equalStrings(ids, tt.wantFailing) {
+				t.Errorf("expected newly failing %v, got %v", tt.wantFailing, ids)
+			}
+			if ids := changeIds(comparison.NewlyPassing); !equalStrings(ids, tt.wantPassing) {
+				t.Errorf("expected newly passing %v, got %v", tt.wantPassing, ids)
+			}
+			if len(comparison.MessageChanged) != tt.wantMessages {
+				t.Errorf("expected %d message changes, got %d", tt.wantMessages, len(comparison.MessageChanged))
+			}
+			if comparison.HasRegressions() != tt.wantRegressions {
+				t.Errorf("expected HasRegressions() = %v", tt.wantRegressions)
+			}
+			if summary := comparison.Summary(); summary != tt.wantSummary {
+				t.Errorf("expected summary %q, got %q", tt.wantSummary, summary)
+			}
+		})
+	}
+}
+
+func TestCompareReversed(t *testing.T) {
+	previous := loadComparisonResults(t)
+	current := loadComparisonResults(t)
+	previous.EvaluationSet[0].Assessments[0].Result = Failed
+
+	comparison := Compare(previous, current)
+	if len(comparison.NewlyPassing) != 1 || comparison.NewlyPassing[0].RequirementId != "OSPS-AC-01.01" {
+		t.Errorf("expected OSPS-AC-01.01 to be newly passing, got %v", comparison.NewlyPassing)
+	}
+	if comparison.HasRegressions() {
+		t.Error("expected no regressions")
+	}
+}
+
+func TestCompareOtherChanges(t *testing.T) {
+	previous := loadComparisonResults(t)
+	current := loadComparisonResults(t)
+	current.EvaluationSet[0].Assessments[0].Result = NeedsReview
+
+	comparison := Compare(previous, current)
+	other := comparison.OtherChanges()
+	if len(other) != 1 || other[0].RequirementId != "OSPS-AC-01.01" || other[0].Result != NeedsReview {
+		t.Errorf("expected OSPS-AC-01.01 to need review, got %v", other)
+	}
+}
+
+func changeIds(changes []ResultChange) []string {
+	var ids []string
+	for _, change := range changes {
+		if change.RequirementId != "" {
+			ids = append(ids, change.RequirementId)
+		} else {
+			ids = append(ids, change.ControlId)
+		}
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
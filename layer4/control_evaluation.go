@@ -1,6 +1,8 @@
 package layer4
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"os"
 	"os/signal"
@@ -10,17 +12,43 @@ import (
 // ControlEvaluation is a struct that contains all assessment results, organized by name.
 type ControlEvaluation struct {
 	// Name is the name of the control being evaluated
-	Name string `yaml:"name"`
+	Name string `json:"name" yaml:"name"`
 	// ControlID is the unique identifier for the control being evaluated
-	ControlID string `yaml:"control-id"`
+	ControlID string `json:"control-id" yaml:"control-id"`
 	// Result is the overall result of the control evaluation
-	Result Result `yaml:"result"`
+	Result Result `json:"result" yaml:"result"`
 	// Message is the human-readable result of the final assessment to run in this evaluation
-	Message string `yaml:"message"`
+	Message string `json:"message" yaml:"message"`
 	// CorruptedState is true if the control evaluation was interrupted and changes were not reverted
-	CorruptedState bool `yaml:"corrupted-state"`
+	CorruptedState bool `json:"corrupted-state" yaml:"corrupted-state"`
+	// CorruptedChanges lists the changes that could not be reverted, leaving their targets modified
+	CorruptedChanges []CorruptedChange `json:"corrupted-changes,omitempty" yaml:"corrupted-changes,omitempty"`
 	// Assessments is a map of pointers to Assessment objects to establish idempotency
-	Assessments []*Assessment `yaml:"assessments"`
+	Assessments []*Assessment `json:"assessments" yaml:"assessments"`
+	// redactor removes secrets from the message when the control evaluation is serialized
+	redactor *Redactor
+}
+
+// controlEvaluationFields is the serialized form of a ControlEvaluation.
+type controlEvaluationFields ControlEvaluation
+
+// MarshalYAML serializes the control evaluation with secrets redacted from its message
+func (c *ControlEvaluation) MarshalYAML() (interface{}, error) {
+	fields := controlEvaluationFields(*c)
+	fields.Message = c.RedactedMessage()
+	return fields, nil
+}
+
+// MarshalJSON serializes the control evaluation with secrets redacted from its message
+func (c *ControlEvaluation) MarshalJSON() ([]byte, error) {
+	fields := controlEvaluationFields(*c)
+	fields.Message = c.RedactedMessage()
+	return json.Marshal(fields)
+}
+
+// RedactedMessage returns the message with secrets redacted, for reports that do not serialize the control evaluation.
+func (c *ControlEvaluation) RedactedMessage() string {
+	return c.redactor.RedactString("message", c.Message)
 }
 
 // AddAssessment creates a new Assessment object and adds it to the ControlEvaluation.
@@ -35,56 +63,122 @@ func (c *ControlEvaluation) AddAssessment(requirementId string, description stri
 }
 
 // Evaluate runs each step in each assessment, updating the relevant fields on the control evaluation.
-// It will halt if a step returns a failed result. The targetData is the data that the assessment will be run against.
-// The userApplicability is a slice of strings that determine when the assessment is applicable. The changesAllowed
-// determines whether the assessment is allowed to execute its changes.
-func (c *ControlEvaluation) Evaluate(targetData interface{}, userApplicability []string, changesAllowed bool) {
+// It will halt if a step returns a failed result, unless the WithContinueOnFailure option is set. The targetData is the data that the assessment will be run against.
+// The userApplicability is a slice of facts against which the applicability expressions of each assessment are
+// evaluated; assessments that do not apply are recorded as not applicable. The changesAllowed
+// determines whether the assessment is allowed to execute its changes, and options may put the changes in plan mode
+// or restrict them to those approved in a plan.
+func (c *ControlEvaluation) Evaluate(targetData interface{}, userApplicability []string, changesAllowed bool, opts ...RunOption) {
 	if len(c.Assessments) == 0 {
 		c.Result = NeedsReview
 		return
 	}
-	c.closeHandler()
+	options := newRunOpts(opts)
+	if options.redactor != nil {
+		c.redactor = options.redactor
+	}
+	defer c.closeHandler(opts...)()
+	var failure string
 	for _, assessment := range c.Assessments {
-		var applicable bool
-		for _, aa := range assessment.Applicability {
-			for _, ua := range userApplicability {
-				if aa == ua {
-					applicable = true
-					break
-				}
+		applicable, err := IsApplicable(assessment.Applicability, userApplicability)
+		if err != nil {
+			assessment.Result = Unknown
+			assessment.Message = err.Error()
+			c.Result = UpdateAggregateResult(c.Result, Unknown)
+			c.Message = assessment.Message
+			continue
+		}
+		if !applicable {
+			if assessment.Result == NotRun {
+				assessment.Result = NotApplicable
+				assessment.Message = fmt.Sprintf("applicability %q does not match the provided facts", assessment.Applicability)
 			}
+			c.Result = UpdateAggregateResult(c.Result, assessment.Result)
+			continue
 		}
-		if applicable {
-			result := assessment.Run(targetData, changesAllowed)
-			c.Result = UpdateAggregateResult(c.Result, result)
-			c.Message = assessment.Message
-			if c.Result == Failed {
-				break
+		result := assessment.Run(targetData, changesAllowed, append([]RunOption{withControlId(c.ControlID)}, opts...)...)
+		c.Result = UpdateAggregateResult(c.Result, result)
+		c.Message = assessment.Message
+		if result == Failed && failure == "" {
+			failure = assessment.Message
+		}
+		if c.Result == Failed && !options.continueOnFailure {
+			break
+		}
+	}
+	if failure != "" {
+		c.Message = failure
+	}
+	c.Cleanup(opts...)
+}
+
+// Reset clears the results of a previous evaluation and of each of its assessments, so that the control can be
+// evaluated again. It returns an error without resetting anything if a change applied by the previous evaluation
+// was not reverted, as its target may still be modified.
+func (c *ControlEvaluation) Reset() error {
+	for _, assessment := range c.Assessments {
+		if assessment != nil {
+			if err := assessment.checkReverted(); err != nil {
+				return err
 			}
 		}
 	}
-	c.Cleanup()
+	for _, assessment := range c.Assessments {
+		if assessment != nil {
+			_ = assessment.Reset()
+		}
+	}
+	c.Result = NotRun
+	c.Message = ""
+	c.CorruptedState = false
+	c.CorruptedChanges = nil
+	return nil
+}
+
+// aggregateAssessments recalculates the control result from the results of its assessments.
+func (c *ControlEvaluation) aggregateAssessments() {
+	c.Result = NotRun
+	for _, assessment := range c.Assessments {
+		if assessment != nil {
+			c.Result = UpdateAggregateResult(c.Result, assessment.Result)
+		}
+	}
 }
 
-// Cleanup reverts all changes made by the ControlEvaluation.
-func (c *ControlEvaluation) Cleanup() {
+// Cleanup reverts all changes made by the ControlEvaluation, most recently applied first across all assessments.
+// Changes that could not be reverted are recorded in CorruptedChanges, and options may retry failed reverts.
+func (c *ControlEvaluation) Cleanup(opts ...RunOption) {
+	var changes []namedChange
 	for _, assessment := range c.Assessments {
-		corrupted := assessment.RevertChanges()
-		if corrupted {
-			c.CorruptedState = true
+		if assessment != nil {
+			changes = append(changes, assessment.namedChanges()...)
 		}
 	}
+	corrupted := revertChanges(changes, opts...)
+	if len(corrupted) > 0 {
+		c.CorruptedState = true
+		c.CorruptedChanges = corrupted
+	}
 }
 
 // closeHandler creates a 'listener' on a new goroutine which will notify the program if it receives an interrupt from the operating system.
 // If an interrupt is received, this will attempt to revert any changes made by the terminated ControlEvaluation.
-func (c *ControlEvaluation) closeHandler() {
+// The returned function stops the listener once the evaluation is complete, so that repeated evaluations do not accumulate listeners.
+func (c *ControlEvaluation) closeHandler(opts ...RunOption) (stop func()) {
 	channel := make(chan os.Signal, 1)
+	done := make(chan struct{})
 	signal.Notify(channel, os.Interrupt, syscall.SIGTERM)
 	go func() {
-		<-channel
-		log.Print("\n*****\nUnexpected termination. Attempting to revert changes made by the active ControlEvaluation. Do not interrupt this process.\n*****\n")
-		c.Cleanup()
-		os.Exit(0)
+		select {
+		case <-channel:
+			log.Print("\n*****\nUnexpected termination. Attempting to revert changes made by the active ControlEvaluation. Do not interrupt this process.\n*****\n")
+			c.Cleanup(opts...)
+			os.Exit(0)
+		case <-done:
+		}
 	}()
+	return func() {
+		signal.Stop(channel)
+		close(done)
+	}
 }
@@ -150,3 +150,42 @@ func TestAddAssesment(t *testing.T) {
 	}
 
 }
+
+func TestEvaluateContinueOnFailure(t *testing.T) {
+	var executed []string
+	step := func(name string, result Result) AssessmentStep {
+		return func(interface{}, map[string]*Change) (Result, string) {
+			executed = append(executed, name)
+			return result, name + " " + result.String()
+		}
+	}
+	newEvaluation := func() *ControlEvaluation {
+		evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+		evaluation.AddAssessment("OSPS-AC-01.01", "First", []string{"Maturity Level 1"}, []AssessmentStep{step("a1", Failed), step("a2", Passed)})
+		evaluation.AddAssessment("OSPS-AC-01.02", "Second", []string{"Maturity Level 1"}, []AssessmentStep{step("b1", NeedsReview), step("b2", Failed)})
+		return evaluation
+	}
+
+	evaluation := newEvaluation()
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, false)
+	if len(executed) != 1 || evaluation.Assessments[1].Result != NotRun {
+		t.Errorf("expected evaluation to halt at the first failure, got steps %v", executed)
+	}
+
+	executed = nil
+	evaluation = newEvaluation()
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, false, WithContinueOnFailure())
+	if len(executed) != 4 {
+		t.Errorf("expected every step to run, got %v", executed)
+	}
+	if evaluation.Result != Failed || evaluation.Message != "a1 Failed" {
+		t.Errorf("expected control to fail with the first failure message, got %s: %s", evaluation.Result, evaluation.Message)
+	}
+	first, second := evaluation.Assessments[0], evaluation.Assessments[1]
+	if first.Result != Failed || first.Message != "a1 Failed" || first.StepsExecuted != 2 || first.End == "" {
+		t.Errorf("unexpected first assessment %s: %s after %d steps", first.Result, first.Message, first.StepsExecuted)
+	}
+	if second.Result != Failed || second.Message != "b2 Failed" || second.StepsExecuted != 2 {
+		t.Errorf("unexpected second assessment %s: %s after %d steps", second.Result, second.Message, second.StepsExecuted)
+	}
+}
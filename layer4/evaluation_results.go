@@ -0,0 +1,7 @@
+package layer4
+
+// EvaluationResults is a struct that contains the control evaluations produced by a single evaluation run.
+type EvaluationResults struct {
+	// EvaluationSet is a slice of pointers to the ControlEvaluation objects that were executed
+	EvaluationSet []*ControlEvaluation `json:"evaluation-set" yaml:"evaluation-set"`
+}
@@ -0,0 +1,227 @@
+package layer4
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultMaxEvidenceSize is the largest inline evidence content, in bytes, that can be attached to an assessment.
+// Larger evidence should be written to a file and attached by reference with AttachEvidenceFile.
+const DefaultMaxEvidenceSize = 1 << 20
+
+// Base64Encoding is the Encoding of inline evidence content that is not valid UTF-8 text.
+const Base64Encoding = "base64"
+
+// Evidence is a named artefact collected during an assessment to support its result.
+// Content is either stored inline or referenced by its URI, and is identified by its digest in both cases.
+type Evidence struct {
+	// Name identifies the evidence within the assessment
+	Name string `json:"name" yaml:"name"`
+	// MediaType is the IANA media type of the content, such as application/json
+	MediaType string `json:"media-type" yaml:"media-type"`
+	// Digest is the SHA-256 digest of the content, in the form sha256:<hex>
+	Digest string `json:"digest" yaml:"digest"`
+	// Size is the size of the content in bytes
+	Size int64 `json:"size" yaml:"size"`
+	// CollectedAt is the time the evidence was collected
+	CollectedAt string `json:"collected-at" yaml:"collected-at"`
+	// Source describes where the evidence was collected from, such as an API endpoint
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+	// Content is the inline content of the evidence
+	Content string `json:"content,omitempty" yaml:"content,omitempty"`
+	// Encoding is "base64" if the inline content is base64 encoded, or empty for text
+	Encoding string `json:"encoding,omitempty" yaml:"encoding,omitempty"`
+	// URI is the location of evidence that is referenced rather than stored inline
+	URI string `json:"uri,omitempty" yaml:"uri,omitempty"`
+}
+
+type evidenceOpts struct {
+	mediaType string
+	source    string
+	maxSize   int64
+}
+
+// EvidenceOption defines an option to tune the evidence attached to an assessment.
+type EvidenceOption func(opts *evidenceOpts)
+
+// WithMediaType is an EvidenceOption that sets the media type of the evidence instead of detecting it.
+func WithMediaType(mediaType string) EvidenceOption {
+	return func(opts *evidenceOpts) {
+		opts.mediaType = mediaType
+	}
+}
+
+// WithEvidenceSource is an EvidenceOption that records where the evidence was collected from.
+func WithEvidenceSource(source string) EvidenceOption {
+	return func(opts *evidenceOpts) {
+		opts.source = source
+	}
+}
+
+// WithMaxEvidenceSize is an EvidenceOption that replaces DefaultMaxEvidenceSize for inline evidence.
+func WithMaxEvidenceSize(size int64) EvidenceOption {
+	return func(opts *evidenceOpts) {
+		opts.maxSize = size
+	}
+}
+
+// AttachEvidence attaches inline evidence to the assessment. It returns an error if the content is larger than
+// the maximum evidence size or if the assessment already has evidence with the same name.
+//
+// Steps can attach evidence by capturing the assessment they belong to:
+//
+//	var assessment *Assessment
+//	assessment, err := NewAssessment(requirementId, description, applicability, []AssessmentStep{
+//		func(payload interface{}, _ map[string]*Change) (Result, string) {
+//			_, err := assessment.AttachEvidence("branch-protection", data, WithMediaType("application/json"))
+//			...
+//		},
+//	})
+func (a *Assessment) AttachEvidence(name string, content []byte, opts ...EvidenceOption) (*Evidence, error) {
+	options := evidenceOpts{maxSize: DefaultMaxEvidenceSize}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if int64(len(content)) > options.maxSize {
+		return nil, fmt.Errorf("evidence %s is %d bytes, which exceeds the maximum of %d bytes; attach it as a file instead", name, len(content), options.maxSize)
+	}
+
+	digest := sha256.Sum256(content)
+	evidence := &Evidence{
+		Name:      name,
+		MediaType: options.mediaType,
+		Digest:    "sha256:" + hex.EncodeToString(digest[:]),
+		Size:      int64(len(content)),
+		Source:    options.source,
+	}
+	if evidence.MediaType == "" {
+		evidence.MediaType = http.DetectContentType(content)
+	}
+	if utf8.Valid(content) {
+		evidence.Content = string(content)
+	} else {
+		evidence.Content = base64.StdEncoding.EncodeToString(content)
+		evidence.Encoding = Base64Encoding
+	}
+	return evidence, a.addEvidence(evidence)
+}
+
+// AttachEvidenceFile attaches evidence that is stored in a file, referenced by its path.
+// The file is hashed so that it can later be verified, but its content is not stored in the results.
+func (a *Assessment) AttachEvidenceFile(name string, path string, opts ...EvidenceOption) (*Evidence, error) {
+	var options evidenceOpts
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	digest, size, sniffed, err := hashFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error hashing evidence %s: %w", name, err)
+	}
+	evidence := &Evidence{
+		Name:      name,
+		MediaType: options.mediaType,
+		Digest:    digest,
+		Size:      size,
+		Source:    options.source,
+		URI:       filepath.ToSlash(path),
+	}
+	if evidence.MediaType == "" {
+		evidence.MediaType = mime.TypeByExtension(filepath.Ext(path))
+	}
+	if evidence.MediaType == "" {
+		evidence.MediaType = http.DetectContentType(sniffed)
+	}
+	return evidence, a.addEvidence(evidence)
+}
+
+func (a *Assessment) addEvidence(evidence *Evidence) error {
+	if evidence.Name == "" {
+		return errors.New("evidence must have a name")
+	}
+	for _, existing := range a.Evidence {
+		if existing.Name == evidence.Name {
+			return fmt.Errorf("assessment %s already has evidence named %s", a.RequirementId, evidence.Name)
+		}
+	}
+	evidence.CollectedAt = time.Now().Format(time.RFC3339)
+	a.Evidence = append(a.Evidence, evidence)
+	return nil
+}
+
+// Bytes returns the inline content of the evidence, decoding it if necessary.
+// It returns an error for evidence that is referenced by URI.
+func (e *Evidence) Bytes() ([]byte, error) {
+	if e.URI != "" {
+		return nil, fmt.Errorf("evidence %s is stored at %s", e.Name, e.URI)
+	}
+	if e.Encoding == Base64Encoding {
+		return base64.StdEncoding.DecodeString(e.Content)
+	}
+	return []byte(e.Content), nil
+}
+
+// Verify checks that the evidence content matches its digest and size.
+// Evidence referenced by a relative file path is resolved against baseDir.
+func (e *Evidence) Verify(baseDir string) error {
+	var digest string
+	var size int64
+	if e.URI == "" {
+		content, err := e.Bytes()
+		if err != nil {
+			return fmt.Errorf("error decoding evidence %s: %w", e.Name, err)
+		}
+		sum := sha256.Sum256(content)
+		digest, size = "sha256:"+hex.EncodeToString(sum[:]), int64(len(content))
+	} else {
+		path := filepath.FromSlash(e.URI)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(baseDir, path)
+		}
+		var err error
+		digest, size, _, err = hashFile(path)
+		if err != nil {
+			return fmt.Errorf("error hashing evidence %s: %w", e.Name, err)
+		}
+	}
+	if !strings.EqualFold(digest, e.Digest) || size != e.Size {
+		return fmt.Errorf("evidence %s does not match its digest", e.Name)
+	}
+	return nil
+}
+
+// hashFile returns the SHA-256 digest and size of a file, along with its first bytes for media type detection.
+func hashFile(path string) (digest string, size int64, sniffed []byte, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	h := sha256.New()
+	sniffed = make([]byte, 512)
+	n, err := io.ReadFull(file, sniffed)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", 0, nil, err
+	}
+	sniffed = sniffed[:n]
+	h.Write(sniffed)
+	rest, err := io.Copy(h, file)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), int64(n) + rest, sniffed, nil
+}
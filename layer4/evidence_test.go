@@ -0,0 +1,100 @@
+package layer4
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestAttachEvidence(t *testing.T) {
+	var assessment *Assessment
+	assessment, err := NewAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []AssessmentStep{
+		func(payload interface{}, _ map[string]*Change) (Result, string) {
+			if _, err := assessment.AttachEvidence("org-settings", []byte(`{"two_factor_requirement_enabled":true}`),
+				WithMediaType("application/json"), WithEvidenceSource("https://api.github.com/orgs/ossf")); err != nil {
+				return Unknown, err.Error()
+			}
+			return Passed, "MFA is required"
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := assessment.Run(nil, false); result != Passed {
+		t.Fatalf("expected the step to attach evidence and pass, got %s: %s", result, assessment.Message)
+	}
+	if len(assessment.Evidence) != 1 {
+		t.Fatalf("expected 1 evidence item, got %d", len(assessment.Evidence))
+	}
+	evidence := assessment.Evidence[0]
+	if evidence.MediaType != "application/json" || evidence.Size != 39 || !strings.HasPrefix(evidence.Digest, "sha256:") || evidence.CollectedAt == "" {
+		t.Errorf("unexpected evidence %+v", evidence)
+	}
+	if err := evidence.Verify(""); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := assessment.AttachEvidence("org-settings", []byte("again")); err == nil {
+		t.Error("expected an error for duplicate evidence names")
+	}
+	if _, err := assessment.AttachEvidence("large", make([]byte, 11), WithMaxEvidenceSize(10)); err == nil {
+		t.Error("expected an error for evidence larger than the maximum size")
+	}
+
+	binary, err := assessment.AttachEvidence("binary", []byte{0xff, 0x00, 0xfe})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if binary.Encoding != Base64Encoding || binary.MediaType != "application/octet-stream" {
+		t.Errorf("expected binary evidence to be base64 encoded, got %+v", binary)
+	}
+	binary.Content = "AAAA"
+	if err := binary.Verify(""); err == nil {
+		t.Error("expected modified evidence to fail verification")
+	}
+}
+
+func TestAttachEvidenceFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "scan.json"), []byte(`{"findings":[]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	assessment := &Assessment{RequirementId: "OSPS-VM-01.01"}
+	evidence, err := assessment.AttachEvidenceFile("scan", filepath.Join(dir, "scan.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evidence.Content != "" || evidence.Size != 15 || evidence.MediaType != "application/json" {
+		t.Errorf("expected the file to be referenced with its size and media type, got %+v", evidence)
+	}
+
+	data, err := yaml.Marshal(assessment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Assessment{}
+	if err := yaml.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Evidence) != 1 || loaded.Evidence[0].Digest != evidence.Digest {
+		t.Fatalf("expected the evidence to round trip, got %+v", loaded.Evidence)
+	}
+	if err := loaded.Evidence[0].Verify(""); err != nil {
+		t.Error(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "scan.json"), []byte(`{"findings":[1]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Evidence[0].Verify(""); err == nil {
+		t.Error("expected a modified file to fail verification")
+	}
+
+	if _, err := assessment.AttachEvidenceFile("missing", filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
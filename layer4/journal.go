@@ -0,0 +1,248 @@
+package layer4
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalEvent is the type of a journal entry.
+type JournalEvent string
+
+const (
+	// JournalApplying is written before a change is applied. A change without a later entry may have modified its target.
+	JournalApplying JournalEvent = "applying"
+	// JournalApplied is written after a change was applied successfully
+	JournalApplied JournalEvent = "applied"
+	// JournalApplyFailed is written when the apply function of a change returned an error
+	JournalApplyFailed JournalEvent = "apply-failed"
+	// JournalReverted is written after a change was reverted, either in process or during recovery
+	JournalReverted JournalEvent = "reverted"
+)
+
+// JournalEntry is a single record in a change journal.
+type JournalEntry struct {
+	// ChangeId identifies the change across its entries
+	ChangeId string `json:"change-id" yaml:"change-id"`
+	// Event is what happened to the change
+	Event JournalEvent `json:"event" yaml:"event"`
+	// Time is when the entry was written
+	Time string `json:"time" yaml:"time"`
+	// TargetName is the name or ID of the changed resource
+	TargetName string `json:"target-name" yaml:"target-name"`
+	// Description is the description of the change
+	Description string `json:"description" yaml:"description"`
+	// Handler is the name of the revert handler used to revert the change during recovery
+	Handler string `json:"handler" yaml:"handler"`
+	// RevertInput is the serialized input passed to the revert handler during recovery
+	RevertInput json.RawMessage `json:"revert-input,omitempty" yaml:"revert-input,omitempty"`
+}
+
+// RevertHandler reverts a change recorded in a journal, using the revert input recorded when it was applied.
+type RevertHandler func(targetName string, revertInput json.RawMessage) error
+
+// Journal is a write-ahead log of applied changes, persisted to disk so that changes can be reverted
+// after the process was killed before it could revert them. Each entry is written as a line of JSON
+// and synced to disk before the change is applied.
+type Journal struct {
+	path     string
+	mu       sync.Mutex
+	handlers map[string]RevertHandler
+}
+
+// OpenJournal opens the journal at the given path, creating it if it does not exist.
+func OpenJournal(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("error opening journal: %w", err)
+	}
+	return &Journal{path: path, handlers: make(map[string]RevertHandler)}, nil
+}
+
+// RegisterRevertHandler registers the handler used to revert changes recorded with the given handler name.
+func (j *Journal) RegisterRevertHandler(name string, handler RevertHandler) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.handlers[name] = handler
+}
+
+// write appends an entry to the journal and syncs it to disk.
+func (j *Journal) write(entry JournalEntry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.writeLocked(entry)
+}
+
+func (j *Journal) writeLocked(entry JournalEntry) error {
+	entry.Time = time.Now().Format(time.RFC3339)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error encoding journal entry: %w", err)
+	}
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_APPEND|os.O_RDWR, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening journal: %w", err)
+	}
+	if err := dropTornEntry(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error writing journal: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error syncing journal: %w", err)
+	}
+	return file.Close()
+}
+
+// dropTornEntry truncates a partially written final entry, left by a process that died while writing it,
+// so that the next entry starts on its own line. The torn entry was written before its change was applied.
+func dropTornEntry(file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("error reading journal: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+	last := make([]byte, 1)
+	if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+		return fmt.Errorf("error reading journal: %w", err)
+	}
+	if last[0] == '\n' {
+		return nil
+	}
+	data := make([]byte, info.Size())
+	if _, err := file.ReadAt(data, 0); err != nil {
+		return fmt.Errorf("error reading journal: %w", err)
+	}
+	if err := file.Truncate(int64(bytes.LastIndexByte(data, '\n') + 1)); err != nil {
+		return fmt.Errorf("error truncating journal: %w", err)
+	}
+	return nil
+}
+
+// Entries returns all entries in the journal, in the order they were written.
+func (j *Journal) Entries() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.entriesLocked()
+}
+
+func (j *Journal) entriesLocked() ([]JournalEntry, error) {
+	file, err := os.Open(j.path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// A partially written final entry means the process died while writing it, before the change was applied
+			if !scanner.Scan() {
+				break
+			}
+			return nil, fmt.Errorf("error decoding journal entry on line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// Pending returns the latest entry of each change that may have modified its target and was not reverted.
+func (j *Journal) Pending() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.pendingLocked()
+}
+
+func (j *Journal) pendingLocked() ([]JournalEntry, error) {
+	entries, err := j.entriesLocked()
+	if err != nil {
+		return nil, err
+	}
+	latest := make(map[string]JournalEntry)
+	var order []string
+	for _, entry := range entries {
+		if _, found := latest[entry.ChangeId]; !found {
+			order = append(order, entry.ChangeId)
+		}
+		latest[entry.ChangeId] = entry
+	}
+	var pending []JournalEntry
+	for _, id := range order {
+		entry := latest[id]
+		if entry.Event == JournalApplying || entry.Event == JournalApplied {
+			pending = append(pending, entry)
+		}
+	}
+	return pending, nil
+}
+
+// Recover reverts the pending changes in the journal using the registered revert handlers, most recent first.
+// It returns the entries of the changes that were reverted. Changes that could not be reverted, including those
+// without a registered handler, remain pending and are reported in the error. Once no changes are pending,
+// the journal is truncated.
+func (j *Journal) Recover() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	pending, err := j.pendingLocked()
+	if err != nil {
+		return nil, err
+	}
+	var reverted []JournalEntry
+	var errs []error
+	for i := len(pending) - 1; i >= 0; i-- {
+		entry := pending[i]
+		handler, found := j.handlers[entry.Handler]
+		if !found {
+			errs = append(errs, fmt.Errorf("no revert handler registered for %q to revert change %s on %s", entry.Handler, entry.ChangeId, entry.TargetName))
+			continue
+		}
+		if err := handler(entry.TargetName, entry.RevertInput); err != nil {
+			errs = append(errs, fmt.Errorf("error reverting change %s on %s: %w", entry.ChangeId, entry.TargetName, err))
+			continue
+		}
+		entry.Event = JournalReverted
+		if err := j.writeLocked(entry); err != nil {
+			return reverted, err
+		}
+		reverted = append(reverted, entry)
+	}
+	if len(errs) > 0 {
+		return reverted, errors.Join(errs...)
+	}
+	if err := os.Truncate(j.path, 0); err != nil {
+		return reverted, fmt.Errorf("error truncating journal: %w", err)
+	}
+	return reverted, nil
+}
+
+func newChangeId() string {
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
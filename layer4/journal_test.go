@@ -0,0 +1,182 @@
+package layer4
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type journalTarget struct {
+	settings map[string]string
+}
+
+func (t *journalTarget) change(journal *Journal) *Change {
+	change := NewChange("repo", "Enable branch protection", nil,
+		func(interface{}) (interface{}, error) {
+			t.settings["branch-protection"] = "enabled"
+			return nil, nil
+		},
+		func(interface{}) error {
+			t.settings["branch-protection"] = "disabled"
+			return nil
+		},
+	)
+	if err := change.EnableJournal(journal, "branch-protection", map[string]string{"branch-protection": "disabled"}); err != nil {
+		panic(err)
+	}
+	change.Allow()
+	return &change
+}
+
+func (t *journalTarget) revertHandler(targetName string, revertInput json.RawMessage) error {
+	var settings map[string]string
+	if err := json.Unmarshal(revertInput, &settings); err != nil {
+		return err
+	}
+	for key, value := range settings {
+		t.settings[key] = value
+	}
+	return nil
+}
+
+func TestJournalRecover(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	target := &journalTarget{settings: map[string]string{"branch-protection": "disabled"}}
+
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if applied, _ := target.change(journal).Apply("repo", nil, nil); !applied {
+		t.Fatal("expected change to be applied")
+	}
+	// The process is killed before the change is reverted
+
+	journal, err = OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pending, err := journal.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Event != JournalApplied || pending[0].TargetName != "repo" {
+		t.Fatalf("expected one applied change to be pending, got %+v", pending)
+	}
+
+	if _, err := journal.Recover(); err == nil {
+		t.Error("expected an error when no revert handler is registered")
+	}
+	if pending, _ := journal.Pending(); len(pending) != 1 {
+		t.Errorf("expected change to remain pending without a revert handler, got %+v", pending)
+	}
+
+	journal.RegisterRevertHandler("branch-protection", target.revertHandler)
+	reverted, err := journal.Recover()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != 1 || target.settings["branch-protection"] != "disabled" {
+		t.Errorf("expected change to be reverted, got %+v with settings %v", reverted, target.settings)
+	}
+	if entries, _ := journal.Entries(); len(entries) != 0 {
+		t.Errorf("expected journal to be truncated after recovery, got %+v", entries)
+	}
+}
+
+func TestJournalRevertInProcess(t *testing.T) {
+	journal, err := OpenJournal(filepath.Join(t.TempDir(), "journal.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := &journalTarget{settings: map[string]string{}}
+	change := target.change(journal)
+	change.Apply("repo", nil, nil)
+	change.Revert(nil)
+
+	entries, err := journal.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := []JournalEvent{JournalApplying, JournalApplied, JournalReverted}
+	if len(entries) != len(events) {
+		t.Fatalf("expected %d journal entries, got %+v", len(events), entries)
+	}
+	for i, event := range events {
+		if entries[i].Event != event || entries[i].ChangeId != entries[0].ChangeId {
+			t.Errorf("expected entry %d to be %s for change %s, got %+v", i, event, entries[0].ChangeId, entries[i])
+		}
+	}
+	if pending, _ := journal.Pending(); len(pending) != 0 {
+		t.Errorf("expected no pending changes, got %+v", pending)
+	}
+}
+
+func TestJournalRevertNotRecorded(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := &journalTarget{settings: map[string]string{}}
+	change := target.change(journal)
+	change.Apply("repo", nil, nil)
+
+	// The journal can no longer be written
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(path, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	change.Revert(nil)
+	if !change.Reverted || target.settings["branch-protection"] != "disabled" {
+		t.Errorf("expected the change to be reverted, got %+v", change)
+	}
+	if change.Error == nil {
+		t.Error("expected an error when the revert cannot be recorded in the journal")
+	}
+}
+
+func TestJournalTruncatedEntry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	journal, err := OpenJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := &journalTarget{settings: map[string]string{}}
+	target.change(journal).Apply("repo", nil, nil)
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = file.WriteString(`{"change-id":"abc","event":"app`)
+	_ = file.Close()
+
+	pending, err := journal.Pending()
+	if err != nil {
+		t.Fatalf("expected a truncated final entry to be ignored, got %v", err)
+	}
+	if len(pending) != 1 {
+		t.Errorf("expected one pending change, got %+v", pending)
+	}
+
+	// The next entry replaces the torn one instead of continuing its line
+	target.change(journal).Apply("repo", nil, nil)
+	pending, err = journal.Pending()
+	if err != nil {
+		t.Fatalf("expected entries written after a truncated entry to be readable, got %v", err)
+	}
+	if len(pending) != 2 {
+		t.Errorf("expected two pending changes, got %+v", pending)
+	}
+}
+
+func TestEnableJournalUnserializableInput(t *testing.T) {
+	change := NewChange("repo", "Enable branch protection", nil, nil, nil)
+	if err := change.EnableJournal(nil, "handler", func() {}); err == nil {
+		t.Error("expected an error for a revert input that cannot be serialized")
+	}
+}
@@ -0,0 +1,148 @@
+package layer4
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ossf/gemara/junit"
+)
+
+// ToJUnit creates a JUnit XML report from the evaluation results, with each control evaluation as a test suite.
+// The report can be written with xml.MarshalIndent, preceded by xml.Header.
+func (e *EvaluationResults) ToJUnit(name string) junit.TestSuites {
+	report := junit.TestSuites{Name: name}
+	var total time.Duration
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		suite, duration := evaluation.toJUnit()
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+		report.Suites = append(report.Suites, suite)
+		total += duration
+	}
+	report.Time = junitSeconds(total)
+	return report
+}
+
+// ToJUnit creates a JUnit XML test suite from the control evaluation, with each assessment as a test case.
+// Failed and NeedsReview assessments are reported as failures, Unknown assessments as errors, and
+// NotApplicable, NotRun and Waived assessments as skipped. Test case times are the sum of the recorded step durations,
+// or are taken from the assessment start and end, to the second, for results recorded without step durations.
+func (c *ControlEvaluation) ToJUnit() junit.TestSuite {
+	suite, _ := c.toJUnit()
+	return suite
+}
+
+func (c *ControlEvaluation) toJUnit() (junit.TestSuite, time.Duration) {
+	suite := junit.TestSuite{
+		Name: c.ControlID,
+		Properties: []junit.Property{
+			{Name: "result", Value: c.Result.String()},
+			{Name: "corrupted-state", Value: strconv.FormatBool(c.CorruptedState)},
+		},
+	}
+	if c.Name != "" {
+		suite.Properties = append([]junit.Property{{Name: "name", Value: c.Name}}, suite.Properties...)
+	}
+
+	var total time.Duration
+	var earliest time.Time
+	for _, assessment := range c.Assessments {
+		if assessment == nil {
+			continue
+		}
+		duration := assessment.duration()
+		total += duration
+		if start, err := time.Parse(time.RFC3339, assessment.Start); err == nil && (earliest.IsZero() || start.Before(earliest)) {
+			earliest = start
+		}
+
+		testCase := junit.TestCase{
+			Name:      assessment.RequirementId,
+			Classname: c.ControlID,
+			Time:      junitSeconds(duration),
+			SystemOut: assessment.junitOutput(),
+		}
+		switch assessment.Result {
+		case Failed, NeedsReview:
+			testCase.Failure = &junit.Result{
+				Message: oneline(assessment.RedactedMessage()),
+				Type:    assessment.Result.String(),
+				Text:    assessment.Recommendation,
+			}
+			suite.Failures++
+		case Unknown:
+			testCase.Error = &junit.Result{
+				Message: oneline(assessment.RedactedMessage()),
+				Type:    assessment.Result.String(),
+			}
+			suite.Errors++
+		case NotApplicable, NotRun:
+			testCase.Skipped = &junit.Skipped{Message: assessment.Result.String()}
+			suite.Skipped++
+		case Waived:
+			message := assessment.Result.String()
+			if assessment.Waiver != nil {
+				message = fmt.Sprintf("Waived by %s until %s: %s", assessment.Waiver.WaiverId, assessment.Waiver.Expires, oneline(assessment.redactor.RedactString("waiver.justification", assessment.Waiver.Justification)))
+			}
+			testCase.Skipped = &junit.Skipped{Message: message}
+			suite.Skipped++
+		}
+		suite.Tests++
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	suite.Time = junitSeconds(total)
+	if !earliest.IsZero() {
+		suite.Timestamp = earliest.UTC().Format("2006-01-02T15:04:05")
+	}
+	return suite, total
+}
+
+// duration returns the time taken by the assessment, or zero if it did not complete.
+func (a *Assessment) duration() time.Duration {
+	if len(a.StepDurations) > 0 {
+		var total float64
+		for _, seconds := range a.StepDurations {
+			total += seconds
+		}
+		return time.Duration(total * float64(time.Second))
+	}
+	start, err := time.Parse(time.RFC3339, a.Start)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(time.RFC3339, a.End)
+	if err != nil || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// junitOutput describes the steps executed by the assessment, with secrets redacted.
+func (a *Assessment) junitOutput() string {
+	steps := a.StepNames()
+	if len(steps) == 0 {
+		return ""
+	}
+	var output strings.Builder
+	fmt.Fprintf(&output, "Steps executed: %d of %d\n", a.StepsExecuted, len(steps))
+	for i, step := range steps {
+		if i < len(a.StepDurations) {
+			fmt.Fprintf(&output, "- %s (%ss)\n", step, junitSeconds(time.Duration(a.StepDurations[i]*float64(time.Second))))
+			continue
+		}
+		fmt.Fprintf(&output, "- %s\n", step)
+	}
+	return a.redactor.RedactString("steps", output.String())
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
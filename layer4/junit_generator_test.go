@@ -0,0 +1,104 @@
+package layer4
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestToJUnit(t *testing.T) {
+	results := &EvaluationResults{
+		EvaluationSet: []*ControlEvaluation{
+			{
+				ControlID: "CTRL-1",
+				Result:    Failed,
+				Assessments: []*Assessment{
+					{
+						RequirementId:  "REQ-1",
+						Result:         Failed,
+						Message:        "Requirement not met",
+						Recommendation: "Fix the configuration",
+						Start:          "2025-08-22T16:02:00Z",
+						End:            "2025-08-22T16:02:01.5Z",
+						StepsExecuted:  1,
+						Steps:          []AssessmentStep{failingAssessmentStep},
+					},
+					{RequirementId: "REQ-2", Result: Unknown, Message: "Could not connect"},
+					{RequirementId: "REQ-3", Result: NotApplicable},
+					{RequirementId: "REQ-4", Result: NotRun},
+					{RequirementId: "REQ-5", Result: Passed, Start: "2025-08-22T16:01:00Z", End: "2025-08-22T16:01:00.25Z"},
+				},
+			},
+			{
+				ControlID:   "CTRL-2",
+				Result:      NeedsReview,
+				Assessments: []*Assessment{{RequirementId: "REQ-6", Result: NeedsReview}},
+			},
+		},
+	}
+
+	report := results.ToJUnit("example")
+	if report.Tests != 6 || report.Failures != 2 || report.Errors != 1 || report.Skipped != 2 {
+		t.Errorf("unexpected totals: tests=%d failures=%d errors=%d skipped=%d", report.Tests, report.Failures, report.Errors, report.Skipped)
+	}
+	if report.Time != "1.750" {
+		t.Errorf("expected total time 1.750, got %s", report.Time)
+	}
+
+	suite := report.Suites[0]
+	if suite.Name != "CTRL-1" || suite.Timestamp != "2025-08-22T16:01:00" || suite.Time != "1.750" {
+		t.Errorf("unexpected suite %s with timestamp %s and time %s", suite.Name, suite.Timestamp, suite.Time)
+	}
+	failing := suite.TestCases[0]
+	if failing.Failure == nil || failing.Failure.Message != "Requirement not met" || failing.Failure.Text != "Fix the configuration" {
+		t.Errorf("expected a failure with the message and recommendation, got %+v", failing.Failure)
+	}
+	if failing.Time != "1.500" || !strings.Contains(failing.SystemOut, "Steps executed: 1 of 1") {
+		t.Errorf("expected step timing and output, got time %s and output %q", failing.Time, failing.SystemOut)
+	}
+	if suite.TestCases[1].Error == nil {
+		t.Error("expected Unknown to be reported as an error")
+	}
+	if suite.TestCases[2].Skipped == nil || suite.TestCases[3].Skipped == nil {
+		t.Error("expected NotApplicable and NotRun to be skipped")
+	}
+	if tc := suite.TestCases[4]; tc.Failure != nil || tc.Error != nil || tc.Skipped != nil {
+		t.Error("expected Passed to be reported as a success")
+	}
+
+	data, err := xml.MarshalIndent(report, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`<testsuites name="example" tests="6" failures="2" errors="1" skipped="2" time="1.750">`,
+		`<testcase name="REQ-1" classname="CTRL-1" time="1.500">`,
+		`<failure message="Requirement not met" type="Failed">Fix the configuration</failure>`,
+		`<skipped message="Not Applicable"></skipped>`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("expected output to contain %s, got:\n%s", want, data)
+		}
+	}
+}
+
+func TestToJUnitStepDurations(t *testing.T) {
+	evaluation := &ControlEvaluation{ControlID: "CTRL-1"}
+	evaluation.AddAssessment("REQ-1", "Slow requirement", []string{"Maturity Level 1"}, []AssessmentStep{
+		func(interface{}, map[string]*Change) (Result, string) {
+			time.Sleep(20 * time.Millisecond)
+			return Passed, "checked"
+		},
+	})
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, false)
+	assessment := evaluation.Assessments[0]
+	if len(assessment.StepDurations) != 1 || assessment.StepDurations[0] < 0.02 {
+		t.Fatalf("expected the duration of the executed step to be recorded, got %v", assessment.StepDurations)
+	}
+
+	testCase := evaluation.ToJUnit().TestCases[0]
+	if testCase.Time == "0.000" || !strings.Contains(testCase.SystemOut, "s)\n") {
+		t.Errorf("expected sub-second step timings, got time %s and output %q", testCase.Time, testCase.SystemOut)
+	}
+}
@@ -0,0 +1,44 @@
+package layer4
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// LoadFile loads evaluation results from a single YAML file at the provided path. JSON support is pending development.
+// If run multiple times, this method will override previous data.
+func (e *EvaluationResults) LoadFile(sourcePath string) error {
+	if !strings.Contains(sourcePath, ".yaml") && !strings.Contains(sourcePath, ".yml") {
+		return fmt.Errorf("unsupported file type")
+	}
+
+	file, err := os.Open(sourcePath)
+	if err != nil {
+		return fmt.Errorf("error opening file: %w", err)
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	err = decode(file, e)
+	if err != nil {
+		return fmt.Errorf("error decoding YAML: %w (%s)", err, sourcePath)
+	}
+	return nil
+}
+
+// decode unmarshals the provided reader into the provided EvaluationResults object.
+// Unknown fields are allowed, as evaluation tools commonly add their own metadata to the results.
+func decode(reader io.Reader, data *EvaluationResults) error {
+	*data = EvaluationResults{}
+	decoder := yaml.NewDecoder(reader)
+	err := decoder.Decode(data)
+	if err != nil {
+		return fmt.Errorf("error decoding YAML: %w", err)
+	}
+	return nil
+}
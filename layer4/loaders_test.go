@@ -0,0 +1,109 @@
+package layer4
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+)
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		sourcePath  string
+		wantErr     bool
+		evaluations int
+	}{
+		{
+			name:        "Privateer scan results",
+			sourcePath:  "./test-data/pvtr-baseline-scan.yaml",
+			evaluations: 39,
+		},
+		{
+			name:       "Missing file",
+			sourcePath: "./test-data/missing.yaml",
+			wantErr:    true,
+		},
+		{
+			name:       "Unsupported file type",
+			sourcePath: "./test-data/pvtr-baseline-scan.txt",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := &EvaluationResults{}
+			err := results.LoadFile(tt.sourcePath)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(results.EvaluationSet) != tt.evaluations {
+				t.Errorf("expected %d evaluations, got %d", tt.evaluations, len(results.EvaluationSet))
+			}
+		})
+	}
+}
+
+func TestAssessmentRoundTrip(t *testing.T) {
+	results := &EvaluationResults{}
+	if err := results.LoadFile("./test-data/pvtr-baseline-scan.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	assessment := results.EvaluationSet[0].Assessments[0]
+	if assessment.Result != Passed {
+		t.Errorf("expected result %v, got %v", Passed, assessment.Result)
+	}
+	wantStep := "github.com/revanite-io/pvtr-github-repo/evaluation_plans/osps/access_control.orgRequiresMFA"
+	if steps := assessment.StepNames(); len(steps) != 1 || steps[0] != wantStep {
+		t.Errorf("expected step names [%s], got %v", wantStep, steps)
+	}
+
+	data, err := yaml.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := &EvaluationResults{}
+	if err := yaml.Unmarshal(data, reloaded); err != nil {
+		t.Fatal(err)
+	}
+	if steps := reloaded.EvaluationSet[0].Assessments[0].StepNames(); len(steps) != 1 || steps[0] != wantStep {
+		t.Errorf("expected step names to survive a round trip, got %v", steps)
+	}
+	if result := reloaded.EvaluationSet[0].Assessments[0].Result; result != Passed {
+		t.Errorf("expected result to survive a round trip, got %v", result)
+	}
+}
+
+func TestLoadedAssessmentCannotRun(t *testing.T) {
+	assessment := &Assessment{}
+	if err := yaml.Unmarshal([]byte("requirement-id: REQ-1\ndescription: loaded\napplicability: [a]\nresult: Not Run\nsteps: [example.step]\n"), assessment); err != nil {
+		t.Fatal(err)
+	}
+	if result := assessment.Run(nil, false); result != Unknown {
+		t.Errorf("expected loaded assessment to produce %v, got %v", Unknown, result)
+	}
+}
+
+func TestAssessmentJSONRoundTrip(t *testing.T) {
+	results := &EvaluationResults{}
+	if err := results.LoadFile("./test-data/pvtr-baseline-scan.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"requirement-id":"OSPS-AC-01.01"`) {
+		t.Errorf("expected kebab-case JSON keys, got %s", data[:200])
+	}
+	reloaded := &EvaluationResults{}
+	if err := json.Unmarshal(data, reloaded); err != nil {
+		t.Fatal(err)
+	}
+	assessment := reloaded.EvaluationSet[0].Assessments[0]
+	if assessment.Result != Passed || len(assessment.StepNames()) != 1 {
+		t.Errorf("expected result and steps to survive a JSON round trip, got %v and %v", assessment.Result, assessment.StepNames())
+	}
+}
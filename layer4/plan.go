@@ -0,0 +1,154 @@
+package layer4
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// PlannedChange is a change that a remediating evaluation would apply, recorded during a run in plan mode.
+type PlannedChange struct {
+	// ControlId is the unique identifier of the control whose evaluation would apply the change
+	ControlId string `json:"control-id" yaml:"control-id"`
+	// RequirementId is the unique identifier of the assessment that would apply the change
+	RequirementId string `json:"requirement-id" yaml:"requirement-id"`
+	// ChangeName is the name of the change within the assessment
+	ChangeName string `json:"change-name" yaml:"change-name"`
+	// TargetName is the name or ID of the resource that would be changed
+	TargetName string `json:"target-name" yaml:"target-name"`
+	// Description is a human-readable description of the change
+	Description string `json:"description" yaml:"description"`
+	// TargetObject is supplemental data describing the object that would be changed
+	TargetObject interface{} `json:"target-object,omitempty" yaml:"target-object,omitempty"`
+	// Approved is true if the change may be applied by a later run with the plan
+	Approved bool `json:"approved" yaml:"approved"`
+
+	// redactor removes secrets from the change when it is serialized
+	redactor *Redactor
+}
+
+// plannedChangeFields is the serialized form of a PlannedChange.
+type plannedChangeFields PlannedChange
+
+func (p PlannedChange) redacted(format string) plannedChangeFields {
+	fields := plannedChangeFields(p)
+	fields.TargetName = p.redactor.RedactString("target-name", fields.TargetName)
+	fields.Description = p.redactor.RedactString("description", fields.Description)
+	fields.TargetObject = p.redactor.redactValue(format, "target-object", fields.TargetObject)
+	return fields
+}
+
+// MarshalYAML serializes the planned change with secrets redacted
+func (p PlannedChange) MarshalYAML() (interface{}, error) {
+	return p.redacted("yaml"), nil
+}
+
+// MarshalJSON serializes the planned change with secrets redacted
+func (p PlannedChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.redacted("json"))
+}
+
+// Plan lists the changes that a remediating evaluation would apply, so that they can be reviewed and approved
+// individually before they are applied.
+type Plan struct {
+	// Changes are the changes that would be applied, in evaluation order
+	Changes []PlannedChange `json:"changes" yaml:"changes"`
+}
+
+// Plan returns the changes recorded as planned by a run in plan mode.
+func (e *EvaluationResults) Plan() Plan {
+	plan := Plan{}
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		for _, assessment := range evaluation.Assessments {
+			if assessment == nil {
+				continue
+			}
+			for _, name := range slices.Sorted(maps.Keys(assessment.Changes)) {
+				change := assessment.Changes[name]
+				if change == nil || !change.Planned {
+					continue
+				}
+				plan.Changes = append(plan.Changes, PlannedChange{
+					ControlId:     evaluation.ControlID,
+					RequirementId: assessment.RequirementId,
+					ChangeName:    name,
+					TargetName:    change.TargetName,
+					Description:   change.Description,
+					TargetObject:  change.TargetObject,
+					redactor:      change.redactor,
+				})
+			}
+		}
+	}
+	return plan
+}
+
+// Approve marks the named change of an assessment in a control evaluation as approved.
+// It returns an error if the plan does not contain the change.
+func (p *Plan) Approve(controlId, requirementId, changeName string) error {
+	for i := range p.Changes {
+		if p.Changes[i].matches(controlId, requirementId, changeName) {
+			p.Changes[i].Approved = true
+			return nil
+		}
+	}
+	return fmt.Errorf("plan does not contain change %s for requirement %s of control %s", changeName, requirementId, controlId)
+}
+
+// ApproveAll marks every change in the plan as approved.
+func (p *Plan) ApproveAll() {
+	for i := range p.Changes {
+		p.Changes[i].Approved = true
+	}
+}
+
+// Approved returns the changes in the plan that were approved.
+func (p *Plan) Approved() []PlannedChange {
+	var approved []PlannedChange
+	for _, change := range p.Changes {
+		if change.Approved {
+			approved = append(approved, change)
+		}
+	}
+	return approved
+}
+
+// approved returns the named change of an assessment in a control evaluation if it was approved.
+func (p *Plan) approved(controlId, requirementId, changeName string) (PlannedChange, bool) {
+	if p == nil {
+		return PlannedChange{}, false
+	}
+	for _, change := range p.Changes {
+		if change.matches(controlId, requirementId, changeName) {
+			return change, change.Approved
+		}
+	}
+	return PlannedChange{}, false
+}
+
+func (p PlannedChange) matches(controlId, requirementId, changeName string) bool {
+	return p.ControlId == controlId && p.RequirementId == requirementId && p.ChangeName == changeName
+}
+
+// WithPlanMode is a RunOption that runs the assessment steps without applying any changes. Each change that a step
+// tries to apply is recorded as planned with its target, and the planned changes can be collected with Plan.
+// Steps see their changes as not applied, so results in plan mode may differ from those of a remediating run.
+func WithPlanMode() RunOption {
+	return func(opts *runOpts) {
+		opts.planOnly = true
+	}
+}
+
+// WithApprovedPlan is a RunOption that restricts the changes allowed in a remediating run to those approved in the plan.
+// Changes are matched by control ID, requirement ID and change name, so the assessments must be run by their
+// ControlEvaluation for their changes to be approved. An approved change is denied if it is applied to a target
+// other than the one in the plan.
+func WithApprovedPlan(plan Plan) RunOption {
+	return func(opts *runOpts) {
+		opts.plan = &plan
+	}
+}
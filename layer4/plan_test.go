@@ -0,0 +1,113 @@
+package layer4
+
+import (
+	"testing"
+)
+
+type planTarget struct {
+	applied []string
+}
+
+func (p *planTarget) evaluation() *ControlEvaluation {
+	evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+	var assessment *Assessment
+	assessment = evaluation.AddAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []AssessmentStep{
+		func(interface{}, map[string]*Change) (Result, string) {
+			for _, name := range []string{"enable-mfa", "remove-members"} {
+				assessment.Changes[name].Apply("ossf", map[string]string{"change": name}, nil)
+			}
+			return Passed, "MFA is required"
+		},
+	})
+	for _, name := range []string{"enable-mfa", "remove-members"} {
+		assessment.NewChange(name, "ossf", "Change "+name, nil,
+			func(interface{}) (interface{}, error) {
+				p.applied = append(p.applied, name)
+				return nil, nil
+			},
+			func(interface{}) error { return nil },
+		)
+	}
+	return evaluation
+}
+
+func TestPlanMode(t *testing.T) {
+	target := &planTarget{}
+	results := &EvaluationResults{EvaluationSet: []*ControlEvaluation{target.evaluation()}}
+	results.EvaluationSet[0].Evaluate(nil, []string{"Maturity Level 1"}, false, WithPlanMode())
+
+	if len(target.applied) != 0 {
+		t.Fatalf("expected no changes to be applied in plan mode, got %v", target.applied)
+	}
+	plan := results.Plan()
+	if len(plan.Changes) != 2 {
+		t.Fatalf("expected 2 planned changes, got %+v", plan.Changes)
+	}
+	first := plan.Changes[0]
+	if first.ControlId != "OSPS-AC-01" || first.RequirementId != "OSPS-AC-01.01" || first.ChangeName != "enable-mfa" ||
+		first.TargetName != "ossf" || first.Description != "Change enable-mfa" || first.Approved {
+		t.Errorf("unexpected planned change %+v", first)
+	}
+
+	if err := plan.Approve("OSPS-AC-01", "OSPS-AC-01.01", "enable-mfa"); err != nil {
+		t.Fatal(err)
+	}
+	if err := plan.Approve("OSPS-AC-01", "OSPS-AC-01.01", "delete-repo"); err == nil {
+		t.Error("expected an error when approving a change that is not in the plan")
+	}
+	if err := plan.Approve("OSPS-AC-02", "OSPS-AC-01.01", "remove-members"); err == nil {
+		t.Error("expected an error when approving a change of another control")
+	}
+	if approved := plan.Approved(); len(approved) != 1 || approved[0].ChangeName != "enable-mfa" {
+		t.Errorf("expected only enable-mfa to be approved, got %+v", approved)
+	}
+
+	target.evaluation().Evaluate(nil, []string{"Maturity Level 1"}, true, WithApprovedPlan(plan))
+	if len(target.applied) != 1 || target.applied[0] != "enable-mfa" {
+		t.Errorf("expected only the approved change to be applied, got %v", target.applied)
+	}
+
+	target.applied = nil
+	target.evaluation().Evaluate(nil, []string{"Maturity Level 1"}, false, WithApprovedPlan(plan))
+	if len(target.applied) != 0 {
+		t.Errorf("expected no changes to be applied when changes are not allowed, got %v", target.applied)
+	}
+
+	other := target.evaluation()
+	other.ControlID = "OSPS-AC-02"
+	other.Evaluate(nil, []string{"Maturity Level 1"}, true, WithApprovedPlan(plan))
+	if len(target.applied) != 0 {
+		t.Errorf("expected approvals not to apply to the same requirement in another control, got %v", target.applied)
+	}
+}
+
+func TestPlanApproveAll(t *testing.T) {
+	plan := Plan{Changes: []PlannedChange{{RequirementId: "a", ChangeName: "x"}, {RequirementId: "b", ChangeName: "y"}}}
+	plan.ApproveAll()
+	if len(plan.Approved()) != 2 {
+		t.Errorf("expected all changes to be approved, got %+v", plan.Changes)
+	}
+}
+
+func TestPlanApprovedTarget(t *testing.T) {
+	target := &planTarget{}
+	plan := Plan{Changes: []PlannedChange{
+		{ControlId: "OSPS-AC-01", RequirementId: "OSPS-AC-01.01", ChangeName: "enable-mfa", TargetName: "ossf-sandbox", Approved: true},
+	}}
+	evaluation := target.evaluation()
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, true, WithApprovedPlan(plan))
+
+	if len(target.applied) != 0 {
+		t.Errorf("expected no changes to be applied to a target other than the approved one, got %v", target.applied)
+	}
+	change := evaluation.Assessments[0].Changes["enable-mfa"]
+	if change.Denial != "change enable-mfa was approved for ossf-sandbox, not ossf" {
+		t.Errorf("unexpected denial %q", change.Denial)
+	}
+
+	plan.Changes[0].TargetName = "ossf"
+	target.evaluation().Evaluate(nil, []string{"Maturity Level 1"}, true, WithApprovedPlan(plan))
+	if len(target.applied) != 1 || target.applied[0] != "enable-mfa" {
+		t.Errorf("expected the change to be applied to the approved target, got %v", target.applied)
+	}
+}
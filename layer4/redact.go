@@ -0,0 +1,307 @@
+package layer4
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-yaml"
+)
+
+// DefaultReplacement is the text that replaces redacted values.
+const DefaultReplacement = "[REDACTED]"
+
+// RedactTag is the struct tag that marks a field as always redacted, as in `redact:"true"`.
+// It applies to the types of values stored in Assessment.Value and Change.TargetObject.
+const RedactTag = "redact"
+
+// SecretFieldPattern matches common names of fields and keys that hold secrets.
+var SecretFieldPattern = regexp.MustCompile(`(?i)(password|passwd|secret|token|api[-_]?key|private[-_]?key|credential|authorization)`)
+
+// maxRedactionDepth limits how deeply nested values are inspected, in case of reference cycles.
+const maxRedactionDepth = 32
+
+// Redactor removes secrets from evaluation results as they are serialized.
+// Redaction is applied to assessment and control messages, assessment values, waiver justifications,
+// attestation comments, and change targets, descriptions and denials, in the serialized results as well as
+// in their SARIF and JUnit reports and rendered documents. Evidence content is not redacted,
+// as it must match its digest. A Redactor is set on results with the WithRedactor run option,
+// or with EvaluationResults.SetRedactor.
+type Redactor struct {
+	fields      []*regexp.Regexp
+	values      []*regexp.Regexp
+	fn          RedactionFunc
+	replacement string
+}
+
+// RedactionFunc is called for each string value with its path, such as "value.settings[0].name",
+// and returns the value to serialize in its place.
+type RedactionFunc func(path string, value string) string
+
+// RedactorOption defines an option to configure a Redactor.
+type RedactorOption func(r *Redactor)
+
+// WithRedactedFields is a RedactorOption that redacts the whole value of struct fields and map keys whose
+// names match any of the patterns. Struct fields are matched by both their Go and serialized names.
+func WithRedactedFields(patterns ...*regexp.Regexp) RedactorOption {
+	return func(r *Redactor) {
+		r.fields = append(r.fields, patterns...)
+	}
+}
+
+// WithRedactedValues is a RedactorOption that redacts the parts of string values that match any of the patterns.
+func WithRedactedValues(patterns ...*regexp.Regexp) RedactorOption {
+	return func(r *Redactor) {
+		r.values = append(r.values, patterns...)
+	}
+}
+
+// WithRedactionFunc is a RedactorOption that sets a callback applied to each string value after the patterns.
+func WithRedactionFunc(fn RedactionFunc) RedactorOption {
+	return func(r *Redactor) {
+		r.fn = fn
+	}
+}
+
+// WithReplacement is a RedactorOption that replaces DefaultReplacement.
+func WithReplacement(replacement string) RedactorOption {
+	return func(r *Redactor) {
+		r.replacement = replacement
+	}
+}
+
+// NewRedactor creates a Redactor. Fields tagged with RedactTag are always redacted.
+func NewRedactor(opts ...RedactorOption) *Redactor {
+	r := &Redactor{replacement: DefaultReplacement}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// WithRedactor is a RunOption that redacts secrets from the results of the run when they are serialized.
+func WithRedactor(r *Redactor) RunOption {
+	return func(opts *runOpts) {
+		opts.redactor = r
+	}
+}
+
+// SetRedactor sets the Redactor applied when the evaluation results are serialized, such as for results
+// that were loaded from a file. A nil Redactor disables redaction.
+func (e *EvaluationResults) SetRedactor(r *Redactor) {
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		evaluation.redactor = r
+		for i := range evaluation.CorruptedChanges {
+			evaluation.CorruptedChanges[i].redactor = r
+		}
+		for _, assessment := range evaluation.Assessments {
+			if assessment != nil {
+				assessment.setRedactor(r)
+			}
+		}
+	}
+}
+
+// firstRedactor returns the first Redactor that is set, for values derived from several results.
+func firstRedactor(redactors ...*Redactor) *Redactor {
+	for _, r := range redactors {
+		if r != nil {
+			return r
+		}
+	}
+	return nil
+}
+
+// RedactString returns the string with secrets removed. The path identifies the value for the RedactionFunc.
+// A nil Redactor returns the string unchanged.
+func (r *Redactor) RedactString(path, value string) string {
+	if r == nil {
+		return value
+	}
+	for _, pattern := range r.values {
+		value = pattern.ReplaceAllLiteralString(value, r.replacement)
+	}
+	if r.fn != nil {
+		value = r.fn(path, value)
+	}
+	return value
+}
+
+// RedactValue returns the value with secrets removed. Values that contain secrets are converted to maps and
+// slices of their JSON form; values without secrets are returned unchanged.
+// A nil Redactor returns the value unchanged.
+func (r *Redactor) RedactValue(path string, value interface{}) interface{} {
+	return r.redactValue("json", path, value)
+}
+
+// redactValue redacts the value as RedactValue does, naming struct fields by their tag for the format,
+// "json" or "yaml".
+func (r *Redactor) redactValue(format, path string, value interface{}) interface{} {
+	if r == nil || value == nil {
+		return value
+	}
+	redacted, changed := r.redact(format, path, reflect.ValueOf(value), 0)
+	if !changed {
+		return value
+	}
+	return redacted
+}
+
+func (r *Redactor) redactsField(names ...string) bool {
+	for _, pattern := range r.fields {
+		for _, name := range names {
+			if name != "" && pattern.MatchString(name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	yamlMarshaler = reflect.TypeOf((*yaml.InterfaceMarshaler)(nil)).Elem()
+	yamlBytes     = reflect.TypeOf((*yaml.BytesMarshaler)(nil)).Elem()
+)
+
+// redact returns the redacted form of the value and whether anything was redacted.
+func (r *Redactor) redact(format, path string, v reflect.Value, depth int) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	if depth > maxRedactionDepth {
+		return v.Interface(), false
+	}
+
+	switch v.Kind() {
+	case reflect.Interface, reflect.Pointer:
+		if v.IsNil() {
+			return v.Interface(), false
+		}
+		if v.Kind() == reflect.Pointer && hasMarshaler(v.Type()) {
+			return v.Interface(), false
+		}
+		redacted, changed := r.redact(format, path, v.Elem(), depth+1)
+		if !changed {
+			return v.Interface(), false
+		}
+		return redacted, true
+	case reflect.String:
+		value := r.RedactString(path, v.String())
+		return value, value != v.String()
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return v.Interface(), false
+		}
+		result := make(map[string]interface{}, v.Len())
+		changed := false
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if r.redactsField(key) {
+				result[key] = r.replacement
+				changed = true
+				continue
+			}
+			value, redacted := r.redact(format, joinRedactionPath(path, key), iter.Value(), depth+1)
+			result[key] = value
+			changed = changed || redacted
+		}
+		return result, changed
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), false
+		}
+		result := make([]interface{}, v.Len())
+		changed := false
+		for i := 0; i < v.Len(); i++ {
+			value, redacted := r.redact(format, fmt.Sprintf("%s[%d]", path, i), v.Index(i), depth+1)
+			result[i] = value
+			changed = changed || redacted
+		}
+		return result, changed
+	case reflect.Struct:
+		if hasMarshaler(v.Type()) {
+			return v.Interface(), false
+		}
+		result := make(map[string]interface{})
+		changed := r.redactStruct(format, path, v, result, depth)
+		return result, changed
+	default:
+		return v.Interface(), false
+	}
+}
+
+// redactStruct adds the redacted exported fields of the struct to the result, using their names in the format.
+func (r *Redactor) redactStruct(format, path string, v reflect.Value, result map[string]interface{}, depth int) bool {
+	changed := false
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, omitEmpty, inline := serializedName(field, format)
+		if name == "-" {
+			continue
+		}
+		value := v.Field(i)
+		if inline && value.Kind() == reflect.Struct {
+			changed = r.redactStruct(format, path, value, result, depth+1) || changed
+			continue
+		}
+		if omitEmpty && value.IsZero() {
+			continue
+		}
+		if field.Tag.Get(RedactTag) == "true" || r.redactsField(field.Name, name) {
+			result[name] = r.replacement
+			changed = true
+			continue
+		}
+		redacted, fieldChanged := r.redact(format, joinRedactionPath(path, name), value, depth+1)
+		result[name] = redacted
+		changed = changed || fieldChanged
+	}
+	return changed
+}
+
+// serializedName returns the name of a struct field from its tag for the format, falling back to
+// its json or yaml tag.
+func serializedName(field reflect.StructField, format string) (name string, omitEmpty bool, inline bool) {
+	tag, found := field.Tag.Lookup(format)
+	if !found {
+		if tag, found = field.Tag.Lookup("json"); !found {
+			tag = field.Tag.Get("yaml")
+		}
+	}
+	name, options, _ := strings.Cut(tag, ",")
+	omitEmpty = strings.Contains(options, "omitempty")
+	inline = strings.Contains(options, "inline") || (field.Anonymous && name == "")
+	if name == "" {
+		name = field.Name
+	}
+	return name, omitEmpty, inline
+}
+
+func hasMarshaler(t reflect.Type) bool {
+	for _, marshaler := range []reflect.Type{jsonMarshaler, textMarshaler, yamlMarshaler, yamlBytes} {
+		if t.Implements(marshaler) || reflect.PointerTo(t).Implements(marshaler) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinRedactionPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
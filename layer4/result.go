@@ -1,6 +1,11 @@
 package layer4
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+)
 
 // Result is an enum representing the result of a control evaluation
 // This is designed to restrict the possible result values to a set of known states
@@ -13,6 +18,7 @@ const (
 	NeedsReview
 	NotApplicable
 	Unknown
+	Waived
 )
 
 var toString = map[Result]string{
@@ -22,6 +28,7 @@ var toString = map[Result]string{
 	NeedsReview:   "Needs Review",
 	NotApplicable: "Not Applicable",
 	Unknown:       "Unknown",
+	Waived:        "Waived",
 }
 
 func (r Result) String() string {
@@ -38,6 +45,44 @@ func (r Result) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.String())
 }
 
+// ParseResult returns the Result for the provided string, as written by Result.String
+func ParseResult(s string) (Result, error) {
+	for result, name := range toString {
+		if name == s {
+			return result, nil
+		}
+	}
+	return Unknown, fmt.Errorf("unknown result: %q", s)
+}
+
+// UnmarshalYAML ensures that Result is deserialized from a string in YAML
+func (r *Result) UnmarshalYAML(data []byte) error {
+	var s string
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	result, err := ParseResult(s)
+	if err != nil {
+		return err
+	}
+	*r = result
+	return nil
+}
+
+// UnmarshalJSON ensures that Result is deserialized from a string in JSON
+func (r *Result) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	result, err := ParseResult(s)
+	if err != nil {
+		return err
+	}
+	*r = result
+	return nil
+}
+
 // UpdateAggregateResult compares the current result with the new result and returns the most severe of the two.
 func UpdateAggregateResult(previous Result, new Result) Result {
 	if new == NotRun {
@@ -47,6 +92,19 @@ func UpdateAggregateResult(previous Result, new Result) Result {
 		return previous
 	}
 
+	if new == NotApplicable {
+		// Not Applicable should only overwrite Not Run, so that a control without applicable assessments is reported as such
+		if previous == NotRun {
+			return NotApplicable
+		}
+		return previous
+	}
+
+	if previous == NotApplicable {
+		// Any result of an applicable assessment should overwrite Not Applicable
+		return new
+	}
+
 	if previous == Failed || new == Failed {
 		// Failed should not be overwritten by anything
 		// Failed should overwrite anything
@@ -63,5 +121,10 @@ func UpdateAggregateResult(previous Result, new Result) Result {
 		// NeedsReview should overwrite Passed
 		return NeedsReview
 	}
+
+	if previous == Waived || new == Waived {
+		// Waived should not be overwritten by Passed, so that accepted risks remain visible
+		return Waived
+	}
 	return Passed
 }
@@ -34,6 +34,10 @@ func TestResultString(t *testing.T) {
 			result:   Unknown,
 			expected: "Unknown",
 		},
+		{
+			result:   Waived,
+			expected: "Waived",
+		},
 	}
 
 	for _, test := range tests {
@@ -95,6 +99,42 @@ func TestUpdateAggregateResult(t *testing.T) {
 			new:      NeedsReview,
 			expected: NeedsReview,
 		},
+		{
+			name:     "Waived should not be overwritten by Passed",
+			prev:     Waived,
+			new:      Passed,
+			expected: Waived,
+		},
+		{
+			name:     "NeedsReview should overwrite Waived",
+			prev:     Waived,
+			new:      NeedsReview,
+			expected: NeedsReview,
+		},
+		{
+			name:     "NotApplicable should overwrite NotRun",
+			prev:     NotRun,
+			new:      NotApplicable,
+			expected: NotApplicable,
+		},
+		{
+			name:     "NotApplicable should not overwrite Passed",
+			prev:     Passed,
+			new:      NotApplicable,
+			expected: Passed,
+		},
+		{
+			name:     "Passed should overwrite NotApplicable",
+			prev:     NotApplicable,
+			new:      Passed,
+			expected: Passed,
+		},
+		{
+			name:     "NeedsReview should overwrite NotApplicable",
+			prev:     NotApplicable,
+			new:      NeedsReview,
+			expected: NeedsReview,
+		},
 	}
 
 	for _, test := range tests {
@@ -0,0 +1,131 @@
+package layer4
+
+import (
+	"encoding/json"
+	"sort"
+	"sync/atomic"
+	"time"
+)
+
+// changeSequence orders changes by the time they were applied, across all assessments.
+var changeSequence atomic.Uint64
+
+// CorruptedChange is a change that could not be reverted, leaving its target modified.
+type CorruptedChange struct {
+	// RequirementId is the unique identifier of the assessment that applied the change
+	RequirementId string `json:"requirement-id" yaml:"requirement-id"`
+	// ChangeName is the name of the change within the assessment
+	ChangeName string `json:"change-name" yaml:"change-name"`
+	// TargetName is the name or ID of the resource that was left modified
+	TargetName string `json:"target-name" yaml:"target-name"`
+	// Description is a human-readable description of the change
+	Description string `json:"description" yaml:"description"`
+	// Error is the last error that prevented the change from being reverted
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// RevertAttempts is the number of times the revert function was executed
+	RevertAttempts int `json:"revert-attempts" yaml:"revert-attempts"`
+
+	// redactor removes secrets from the change when it is serialized
+	redactor *Redactor
+}
+
+// corruptedChangeFields is the serialized form of a CorruptedChange.
+type corruptedChangeFields CorruptedChange
+
+func (c CorruptedChange) redacted() corruptedChangeFields {
+	redactor := c.redactor
+	fields := corruptedChangeFields(c)
+	fields.TargetName = redactor.RedactString("target-name", fields.TargetName)
+	fields.Description = redactor.RedactString("description", fields.Description)
+	fields.Error = redactor.RedactString("error", fields.Error)
+	return fields
+}
+
+// MarshalYAML serializes the corrupted change with secrets redacted
+func (c CorruptedChange) MarshalYAML() (interface{}, error) {
+	return c.redacted(), nil
+}
+
+// MarshalJSON serializes the corrupted change with secrets redacted
+func (c CorruptedChange) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.redacted())
+}
+
+// WithRevertRetries is a RunOption that retries a failed revert up to the given number of times,
+// waiting for the backoff before the first retry and doubling it before each further retry.
+func WithRevertRetries(retries int, backoff time.Duration) RunOption {
+	return func(opts *runOpts) {
+		opts.revertRetries = retries
+		opts.revertBackoff = backoff
+	}
+}
+
+// namedChange is a change with the assessment and name it was registered with.
+type namedChange struct {
+	requirementId string
+	name          string
+	change        *Change
+}
+
+// revertChanges reverts the changes that were applied or have an error, most recently applied first.
+// Every change is attempted, and the changes that could not be reverted are returned.
+func revertChanges(changes []namedChange, opts ...RunOption) (corrupted []CorruptedChange) {
+	options := newRunOpts(opts)
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].change.sequence != changes[j].change.sequence {
+			return changes[i].change.sequence > changes[j].change.sequence
+		}
+		if changes[i].requirementId != changes[j].requirementId {
+			return changes[i].requirementId < changes[j].requirementId
+		}
+		return changes[i].name < changes[j].name
+	})
+	for _, named := range changes {
+		change := named.change
+		if change == nil || change.readOnly || !(change.Applied || change.Error != nil) {
+			continue
+		}
+		if !change.Reverted {
+			change.revertWithRetries(options.revertRetries, options.revertBackoff)
+		}
+		if change.Error != nil || !change.Reverted {
+			record := CorruptedChange{
+				RequirementId:  named.requirementId,
+				ChangeName:     named.name,
+				TargetName:     change.TargetName,
+				Description:    change.Description,
+				RevertAttempts: change.RevertAttempts,
+				redactor:       change.redactor,
+			}
+			if change.Error != nil {
+				record.Error = change.Error.Error()
+			}
+			corrupted = append(corrupted, record)
+		}
+	}
+	return corrupted
+}
+
+// revertWithRetries reverts the change, retrying failures of the revert function with exponential backoff.
+func (c *Change) revertWithRetries(retries int, backoff time.Duration) {
+	for attempt := 0; ; attempt++ {
+		attempts := c.RevertAttempts
+		c.Revert(nil)
+		// Only failures of the revert function are retried, not changes that cannot be reverted at all
+		if c.Reverted || c.RevertAttempts == attempts || attempt >= retries {
+			return
+		}
+		c.Error = nil
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// namedChanges returns the changes of the assessment with their names.
+func (a *Assessment) namedChanges() []namedChange {
+	changes := make([]namedChange, 0, len(a.Changes))
+	for name, change := range a.Changes {
+		changes = append(changes, namedChange{requirementId: a.RequirementId, name: name, change: change})
+	}
+	return changes
+}
@@ -0,0 +1,40 @@
+package layer4
+
+import (
+	"time"
+)
+
+type runOpts struct {
+	planOnly    bool
+	plan        *Plan
+	authorizer  ChangeAuthorizer
+	environment string
+	controlId   string
+
+	revertRetries int
+	revertBackoff time.Duration
+
+	continueOnFailure bool
+
+	redactor *Redactor
+}
+
+// RunOption defines an option to tune how assessments and control evaluations are run and how they handle their changes.
+type RunOption func(opts *runOpts)
+
+func newRunOpts(opts []RunOption) runOpts {
+	var options runOpts
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithContinueOnFailure is a RunOption that runs every step of each assessment and every applicable assessment of
+// a control evaluation, instead of halting at the first failure, so that a single run reports every failure.
+// Results are still aggregated, and the message of the first failure is kept.
+func WithContinueOnFailure() RunOption {
+	return func(opts *runOpts) {
+		opts.continueOnFailure = true
+	}
+}
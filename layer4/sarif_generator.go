@@ -0,0 +1,171 @@
+package layer4
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ossf/gemara/layer2"
+	"github.com/ossf/gemara/sarif"
+)
+
+const defaultSARIFDriver = "gemara"
+
+type sarifOpts struct {
+	driverName     string
+	driverVersion  string
+	informationURI string
+	artifactURI    string
+}
+
+// SARIFOption defines an option to tune the behavior of the SARIF
+// generation function.
+type SARIFOption func(opts *sarifOpts)
+
+// WithToolDriver is a SARIFOption that identifies the tool that produced the evaluation results,
+// such as a Privateer plugin. If not set, the tool is reported as "gemara".
+func WithToolDriver(name, version, informationURI string) SARIFOption {
+	return func(opts *sarifOpts) {
+		opts.driverName = name
+		opts.driverVersion = version
+		opts.informationURI = informationURI
+	}
+}
+
+// WithArtifactURI is a SARIFOption that sets the location reported for every result, such as the
+// path or URL of the evaluated repository. Consumers like GitHub code scanning require a location.
+func WithArtifactURI(uri string) SARIFOption {
+	return func(opts *sarifOpts) {
+		opts.artifactURI = uri
+	}
+}
+
+// ToSARIF creates a SARIF 2.1.0 log from the evaluation results. Each assessment requirement in the
+// Layer 2 catalog becomes a rule, with its recommendation as help text. Each failed or needs-review
+// assessment becomes a result with the level "error" or "warning" respectively. The catalog may be nil,
+// in which case rules are created from the assessments themselves.
+func (e *EvaluationResults) ToSARIF(catalog *layer2.Catalog, opts ...SARIFOption) (sarif.Log, error) {
+	options := sarifOpts{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.driverName == "" {
+		options.driverName = defaultSARIFDriver
+	}
+
+	driver := sarif.Driver{
+		Name:           options.driverName,
+		Version:        options.driverVersion,
+		InformationURI: options.informationURI,
+	}
+	ruleIndex := make(map[string]int)
+	addRule := func(rule sarif.Rule) {
+		if _, exists := ruleIndex[rule.Id]; exists {
+			return
+		}
+		ruleIndex[rule.Id] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, rule)
+	}
+
+	if catalog != nil {
+		for _, family := range catalog.ControlFamilies {
+			for _, control := range family.Controls {
+				for _, requirement := range control.AssessmentRequirements {
+					addRule(sarif.Rule{
+						Id:               requirement.Id,
+						ShortDescription: &sarif.Message{Text: fmt.Sprintf("%s: %s", control.Id, oneline(control.Title))},
+						FullDescription:  &sarif.Message{Text: oneline(requirement.Text)},
+						Help:             helpMessage(requirement.Recommendation),
+						Properties: map[string]interface{}{
+							"control-id":    control.Id,
+							"applicability": requirement.Applicability,
+						},
+					})
+				}
+			}
+		}
+	}
+
+	run := sarif.Run{Results: []sarif.Result{}}
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		for _, assessment := range evaluation.Assessments {
+			if assessment == nil {
+				continue
+			}
+			var level string
+			switch assessment.Result {
+			case Failed:
+				level = sarif.LevelError
+			case NeedsReview:
+				level = sarif.LevelWarning
+			default:
+				continue
+			}
+			if assessment.RequirementId == "" {
+				return sarif.Log{}, fmt.Errorf("assessment for control %s does not have a requirement id", evaluation.ControlID)
+			}
+
+			addRule(sarif.Rule{
+				Id:               assessment.RequirementId,
+				ShortDescription: &sarif.Message{Text: evaluation.ControlID},
+				FullDescription:  &sarif.Message{Text: oneline(assessment.Description)},
+				Help:             helpMessage(assessment.Recommendation),
+				Properties: map[string]interface{}{
+					"control-id":    evaluation.ControlID,
+					"applicability": assessment.Applicability,
+				},
+			})
+
+			result := sarif.Result{
+				RuleId:    assessment.RequirementId,
+				RuleIndex: ruleIndex[assessment.RequirementId],
+				Level:     level,
+				Message:   sarif.Message{Text: resultMessage(assessment)},
+				Properties: map[string]interface{}{
+					"control-id":    evaluation.ControlID,
+					"applicability": assessment.Applicability,
+					"result":        assessment.Result.String(),
+				},
+			}
+			if options.artifactURI != "" {
+				result.Locations = []sarif.Location{
+					{PhysicalLocation: sarif.PhysicalLocation{ArtifactLocation: sarif.ArtifactLocation{URI: options.artifactURI}}},
+				}
+			}
+			run.Results = append(run.Results, result)
+		}
+	}
+
+	run.Tool = sarif.Tool{Driver: driver}
+	return sarif.Log{
+		Schema:  sarif.Schema,
+		Version: sarif.Version,
+		Runs:    []sarif.Run{run},
+	}, nil
+}
+
+func helpMessage(recommendation string) *sarif.Message {
+	if strings.TrimSpace(recommendation) == "" {
+		return nil
+	}
+	return &sarif.Message{Text: oneline(recommendation)}
+}
+
+// resultMessage returns the message of the assessment with secrets redacted, falling back to its description,
+// as SARIF results must have a message.
+func resultMessage(assessment *Assessment) string {
+	if message := oneline(assessment.RedactedMessage()); message != "" {
+		return message
+	}
+	if description := oneline(assessment.Description); description != "" {
+		return description
+	}
+	return fmt.Sprintf("%s: %s", assessment.RequirementId, assessment.Result)
+}
+
+// oneline collapses all whitespace, such as newlines in YAML block scalars, into single spaces.
+func oneline(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
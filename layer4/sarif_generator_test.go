@@ -0,0 +1,122 @@
+package layer4
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ossf/gemara/layer2"
+	"github.com/ossf/gemara/sarif"
+)
+
+func sarifCatalog() *layer2.Catalog {
+	return &layer2.Catalog{
+		ControlFamilies: []layer2.ControlFamily{
+			{
+				Id:    "AC",
+				Title: "Access Control",
+				Controls: []layer2.Control{
+					{
+						Id:    "OSPS-AC-01",
+						Title: "Require multi-factor authentication",
+						AssessmentRequirements: []layer2.AssessmentRequirement{
+							{
+								Id:             "OSPS-AC-01.01",
+								Text:           "The system MUST require multi-factor authentication.",
+								Applicability:  []string{"Maturity Level 1"},
+								Recommendation: "Enable two-factor authentication for the organization.",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestToSARIF(t *testing.T) {
+	results := &EvaluationResults{}
+	if err := results.LoadFile("./test-data/pvtr-baseline-scan.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	results.EvaluationSet[0].Assessments[0].Result = Failed
+	results.EvaluationSet[0].Assessments[0].Message = "Two-factor authentication is not required"
+
+	log, err := results.ToSARIF(sarifCatalog(), WithToolDriver("pvtr-github-repo", "0.1.0", ""), WithArtifactURI("https://github.com/ossf/gemara"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != sarif.Version || len(log.Runs) != 1 {
+		t.Fatalf("expected a single SARIF %s run, got version %q with %d runs", sarif.Version, log.Version, len(log.Runs))
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "pvtr-github-repo" {
+		t.Errorf("expected driver name pvtr-github-repo, got %q", run.Tool.Driver.Name)
+	}
+
+	rule := run.Tool.Driver.Rules[0]
+	if rule.Id != "OSPS-AC-01.01" || rule.Help == nil || rule.Help.Text != "Enable two-factor authentication for the organization." {
+		t.Errorf("expected the catalog requirement as the first rule with its recommendation as help, got %+v", rule)
+	}
+
+	levels := make(map[string]int)
+	for _, result := range run.Results {
+		levels[result.Level]++
+		if run.Tool.Driver.Rules[result.RuleIndex].Id != result.RuleId {
+			t.Errorf("rule index %d does not match rule %s", result.RuleIndex, result.RuleId)
+		}
+		if result.Message.Text == "" {
+			t.Errorf("expected a message for result %s", result.RuleId)
+		}
+		if len(result.Locations) != 1 {
+			t.Errorf("expected a location for result %s", result.RuleId)
+		}
+	}
+	if levels[sarif.LevelError] != 5 || levels[sarif.LevelWarning] != 4 {
+		t.Errorf("expected 5 errors and 4 warnings, got %v", levels)
+	}
+
+	first := run.Results[0]
+	if first.RuleId != "OSPS-AC-01.01" || first.Properties["control-id"] != "OSPS-AC-01" {
+		t.Errorf("expected the first result to be for OSPS-AC-01.01, got %+v", first)
+	}
+
+	data, err := json.Marshal(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"$schema":"https://json.schemastore.org/sarif-2.1.0.json"`) {
+		t.Errorf("expected the SARIF schema in the output, got %s", data)
+	}
+}
+
+func TestToSARIFWithoutCatalog(t *testing.T) {
+	results := &EvaluationResults{
+		EvaluationSet: []*ControlEvaluation{
+			{
+				ControlID: "CTRL-1",
+				Assessments: []*Assessment{
+					{RequirementId: "REQ-1", Description: "Requirement description", Result: NeedsReview},
+					{RequirementId: "REQ-2", Result: Passed},
+				},
+			},
+		},
+	}
+	log, err := results.ToSARIF(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "gemara" {
+		t.Errorf("expected default driver name, got %q", run.Tool.Driver.Name)
+	}
+	if len(run.Tool.Driver.Rules) != 1 || len(run.Results) != 1 {
+		t.Fatalf("expected one rule and one result, got %d and %d", len(run.Tool.Driver.Rules), len(run.Results))
+	}
+	if run.Results[0].Level != sarif.LevelWarning || run.Results[0].Message.Text != "Requirement description" {
+		t.Errorf("unexpected result %+v", run.Results[0])
+	}
+	if len(run.Results[0].Locations) != 0 {
+		t.Errorf("expected no locations without an artifact URI")
+	}
+}
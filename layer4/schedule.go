@@ -0,0 +1,253 @@
+package layer4
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of evaluation runs retained by a Scheduler by default.
+const DefaultHistorySize = 10
+
+// EvaluationRun records the results of one scheduled evaluation run.
+type EvaluationRun struct {
+	// Start is the time the run began
+	Start string `json:"start" yaml:"start"`
+	// End is the time the run finished
+	End string `json:"end" yaml:"end"`
+	// Error is the reason the run could not be completed, if any
+	Error string `json:"error,omitempty" yaml:"error,omitempty"`
+	// Results are the results of the run, independent of later runs
+	Results *EvaluationResults `json:"results,omitempty" yaml:"results,omitempty"`
+}
+
+// TargetDataFunc returns the data that the assessments are run against, fetched again for each run.
+type TargetDataFunc func() (interface{}, error)
+
+type schedulerOpts struct {
+	interval       time.Duration
+	historySize    int
+	changesAllowed bool
+	runOptions     []RunOption
+	onRun          func(EvaluationRun)
+}
+
+// SchedulerOption defines an option to tune the behavior of a Scheduler.
+type SchedulerOption func(opts *schedulerOpts)
+
+// WithInterval is a SchedulerOption that sets the time between the start of consecutive runs. The default is one hour.
+func WithInterval(interval time.Duration) SchedulerOption {
+	return func(opts *schedulerOpts) {
+		opts.interval = interval
+	}
+}
+
+// WithHistorySize is a SchedulerOption that sets the number of runs retained in the history, oldest first.
+func WithHistorySize(size int) SchedulerOption {
+	return func(opts *schedulerOpts) {
+		opts.historySize = size
+	}
+}
+
+// WithChangesAllowed is a SchedulerOption that allows the assessments to execute their changes in each run.
+func WithChangesAllowed() SchedulerOption {
+	return func(opts *schedulerOpts) {
+		opts.changesAllowed = true
+	}
+}
+
+// WithRunOptions is a SchedulerOption that sets the options used to evaluate the controls in each run.
+func WithRunOptions(opts ...RunOption) SchedulerOption {
+	return func(o *schedulerOpts) {
+		o.runOptions = opts
+	}
+}
+
+// WithRunCallback is a SchedulerOption that calls the function after each run, such as to publish its results.
+func WithRunCallback(onRun func(EvaluationRun)) SchedulerOption {
+	return func(opts *schedulerOpts) {
+		opts.onRun = onRun
+	}
+}
+
+// Scheduler evaluates the same control evaluations repeatedly, resetting them before each run
+// and retaining the results of recent runs. Attestations added to the Scheduler are applied
+// to each run until they expire.
+type Scheduler struct {
+	evaluations       []*ControlEvaluation
+	userApplicability []string
+	options           schedulerOpts
+
+	// running serializes runs, which reset and evaluate the same control evaluations
+	running sync.Mutex
+
+	mu           sync.Mutex
+	history      []EvaluationRun
+	attestations []Attestation
+}
+
+// NewScheduler creates a Scheduler for the control evaluations, evaluated against the user applicability in each run.
+func NewScheduler(evaluations []*ControlEvaluation, userApplicability []string, opts ...SchedulerOption) *Scheduler {
+	options := schedulerOpts{
+		interval:    time.Hour,
+		historySize: DefaultHistorySize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return &Scheduler{
+		evaluations:       evaluations,
+		userApplicability: userApplicability,
+		options:           options,
+	}
+}
+
+// RunOnce resets the control evaluations, evaluates them against the target data and records the run in the history.
+// It returns an error without running if an evaluation cannot be reset because a change from a previous run was not
+// reverted. Runs do not overlap: a call made while another run is in progress, including one started by Run, waits
+// for it to finish.
+func (s *Scheduler) RunOnce(targetData interface{}) (EvaluationRun, error) {
+	s.running.Lock()
+	run := EvaluationRun{Start: time.Now().Format(time.RFC3339)}
+	err := s.evaluate(targetData)
+	if err != nil {
+		run.Error = err.Error()
+	} else {
+		run.Results = s.snapshot()
+	}
+	run.End = time.Now().Format(time.RFC3339)
+	s.running.Unlock()
+	s.record(run)
+	return run, err
+}
+
+func (s *Scheduler) evaluate(targetData interface{}) error {
+	for _, evaluation := range s.evaluations {
+		if err := evaluation.Reset(); err != nil {
+			return err
+		}
+	}
+	for _, evaluation := range s.evaluations {
+		evaluation.Evaluate(targetData, s.userApplicability, s.options.changesAllowed, s.options.runOptions...)
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	s.attestations = slices.DeleteFunc(s.attestations, func(attestation Attestation) bool {
+		return attestation.expired(now)
+	})
+	attestations := slices.Clone(s.attestations)
+	s.mu.Unlock()
+	if len(attestations) == 0 {
+		return nil
+	}
+	results := &EvaluationResults{EvaluationSet: s.evaluations}
+	_, err := results.ApplyAttestations(attestations, WithAttestationTime(now))
+	return err
+}
+
+// Attest adds attestations that are applied to the assessments needing review in the following runs,
+// until they expire. It returns an error without adding any attestation if one of them is invalid.
+func (s *Scheduler) Attest(attestations ...Attestation) error {
+	for _, attestation := range attestations {
+		if err := attestation.validate(); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attestations = append(s.attestations, attestations...)
+	return nil
+}
+
+// Run evaluates the controls immediately and then at each interval until the context is cancelled, fetching the
+// target data before each run. Runs whose target data cannot be fetched are recorded with their error. Run stops
+// with an error if an evaluation cannot be reset, as a target may have been left modified.
+func (s *Scheduler) Run(ctx context.Context, targetData TargetDataFunc) error {
+	if s.options.interval <= 0 {
+		return fmt.Errorf("scheduler interval must be positive, got %s", s.options.interval)
+	}
+	ticker := time.NewTicker(s.options.interval)
+	defer ticker.Stop()
+	for {
+		data, err := targetData()
+		if err != nil {
+			now := time.Now().Format(time.RFC3339)
+			s.record(EvaluationRun{Start: now, End: now, Error: fmt.Sprintf("error fetching target data: %s", err)})
+		} else if _, err := s.RunOnce(data); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return nil
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// History returns the retained runs, oldest first.
+func (s *Scheduler) History() []EvaluationRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.history)
+}
+
+// Latest returns the most recent run, and false if no run was recorded yet.
+func (s *Scheduler) Latest() (EvaluationRun, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.history) == 0 {
+		return EvaluationRun{}, false
+	}
+	return s.history[len(s.history)-1], true
+}
+
+func (s *Scheduler) record(run EvaluationRun) {
+	s.mu.Lock()
+	s.history = append(s.history, run)
+	if s.options.historySize > 0 && len(s.history) > s.options.historySize {
+		s.history = slices.Clone(s.history[len(s.history)-s.options.historySize:])
+	}
+	s.mu.Unlock()
+	if s.options.onRun != nil {
+		s.options.onRun(run)
+	}
+}
+
+// snapshot copies the results of the control evaluations, so that they are not modified by later runs.
+func (s *Scheduler) snapshot() *EvaluationResults {
+	results := &EvaluationResults{}
+	for _, evaluation := range s.evaluations {
+		results.EvaluationSet = append(results.EvaluationSet, evaluation.snapshot())
+	}
+	return results
+}
+
+// snapshot returns a copy of the control evaluation, its assessments and their changes.
+func (c *ControlEvaluation) snapshot() *ControlEvaluation {
+	copied := *c
+	copied.CorruptedChanges = slices.Clone(c.CorruptedChanges)
+	copied.Assessments = make([]*Assessment, len(c.Assessments))
+	for i, assessment := range c.Assessments {
+		if assessment == nil {
+			continue
+		}
+		copiedAssessment := *assessment
+		copiedAssessment.Evidence = slices.Clone(assessment.Evidence)
+		if assessment.Changes != nil {
+			copiedAssessment.Changes = make(map[string]*Change, len(assessment.Changes))
+			for name, change := range assessment.Changes {
+				copiedChange := *change
+				copiedAssessment.Changes[name] = &copiedChange
+			}
+		}
+		copied.Assessments[i] = &copiedAssessment
+	}
+	return &copied
+}
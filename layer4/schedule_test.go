@@ -0,0 +1,168 @@
+package layer4
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func scheduledEvaluation() *ControlEvaluation {
+	evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+	evaluation.AddAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []AssessmentStep{
+		func(payload interface{}, _ map[string]*Change) (Result, string) {
+			if payload.(bool) {
+				return Passed, "MFA is required"
+			}
+			return Failed, "MFA is not required"
+		},
+	})
+	return evaluation
+}
+
+func TestAssessmentReset(t *testing.T) {
+	evaluation := scheduledEvaluation()
+	assessment := evaluation.Assessments[0]
+	evaluation.Evaluate(false, []string{"Maturity Level 1"}, false)
+	if assessment.Result != Failed {
+		t.Fatalf("expected first run to fail, got %s", assessment.Result)
+	}
+	if err := evaluation.Reset(); err != nil {
+		t.Fatal(err)
+	}
+	if assessment.Result != NotRun || assessment.Message != "" || assessment.StepsExecuted != 0 || assessment.Start != "" || evaluation.Result != NotRun {
+		t.Errorf("expected results to be cleared, got %+v", assessment)
+	}
+	if len(assessment.Steps) != 1 || assessment.RequirementId != "OSPS-AC-01.01" {
+		t.Errorf("expected configuration to be kept, got %+v", assessment)
+	}
+	evaluation.Evaluate(true, []string{"Maturity Level 1"}, false)
+	if assessment.Result != Passed || evaluation.Result != Passed {
+		t.Errorf("expected second run to pass, got %s", assessment.Result)
+	}
+}
+
+func TestAssessmentResetWithUnrevertedChange(t *testing.T) {
+	assessment := &Assessment{RequirementId: "OSPS-AC-01.01", Result: Passed}
+	change := assessment.NewChange("enable-mfa", "ossf", "Enable MFA", nil,
+		func(interface{}) (interface{}, error) { return nil, nil },
+		func(interface{}) error { return errors.New("permission denied") },
+	)
+	change.Allow()
+	change.Apply("ossf", nil, nil)
+	if err := assessment.Reset(); err == nil || assessment.Result != Passed {
+		t.Errorf("expected an assessment with an unreverted change not to be reset, got %v", err)
+	}
+	change.Revert(nil)
+	if err := assessment.Reset(); err == nil {
+		t.Error("expected an assessment whose change failed to revert not to be reset")
+	}
+}
+
+func TestSchedulerHistory(t *testing.T) {
+	scheduler := NewScheduler([]*ControlEvaluation{scheduledEvaluation()}, []string{"Maturity Level 1"}, WithHistorySize(2))
+	for _, data := range []bool{false, true, true} {
+		if _, err := scheduler.RunOnce(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	history := scheduler.History()
+	if len(history) != 2 {
+		t.Fatalf("expected 2 runs to be retained, got %d", len(history))
+	}
+	first := history[0].Results.EvaluationSet[0].Assessments[0]
+	if first.Result != Passed || first.StepsExecuted != 1 {
+		t.Errorf("expected retained runs to keep their own results, got %s after %d steps", first.Result, first.StepsExecuted)
+	}
+}
+
+func TestSchedulerRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var runs []EvaluationRun
+	scheduler := NewScheduler([]*ControlEvaluation{scheduledEvaluation()}, []string{"Maturity Level 1"},
+		WithInterval(time.Millisecond),
+		WithRunCallback(func(run EvaluationRun) {
+			runs = append(runs, run)
+			if len(runs) == 3 {
+				cancel()
+			}
+		}),
+	)
+	calls := 0
+	err := scheduler.Run(ctx, func() (interface{}, error) {
+		calls++
+		if calls == 2 {
+			return nil, errors.New("rate limited")
+		}
+		return calls%2 == 1, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 3 || runs[1].Error == "" || runs[1].Results != nil {
+		t.Fatalf("expected 3 runs with the second failing to fetch target data, got %+v", runs)
+	}
+	for _, i := range []int{0, 2} {
+		if result := runs[i].Results.EvaluationSet[0].Result; result != Passed {
+			t.Errorf("expected run %d to pass, got %s", i, result)
+		}
+	}
+	if latest, ok := scheduler.Latest(); !ok || latest.Start != runs[2].Start {
+		t.Errorf("expected latest run to be the third run, got %+v", latest)
+	}
+}
+
+func TestSchedulerAttestations(t *testing.T) {
+	evaluation := &ControlEvaluation{ControlID: "OSPS-GV-02"}
+	evaluation.AddAssessment("OSPS-GV-02.01", "Discussion forum", []string{"Maturity Level 1"}, []AssessmentStep{
+		func(interface{}, map[string]*Change) (Result, string) {
+			return NeedsReview, "Discussion forum could not be verified"
+		},
+	})
+	scheduler := NewScheduler([]*ControlEvaluation{evaluation}, []string{"Maturity Level 1"})
+	if err := scheduler.Attest(Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Decision: Passed}); err == nil {
+		t.Error("expected an attestation without an attester to be rejected")
+	}
+	err := scheduler.Attest(
+		Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "alice", AttestedOn: "2025-05-01T00:00:00Z", Decision: Passed},
+		Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "bob", AttestedOn: "2025-06-01T00:00:00Z", Decision: Failed, Expires: "2025-07-01T00:00:00Z"},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		run, err := scheduler.RunOnce(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assessment := run.Results.EvaluationSet[0].Assessments[0]
+		if assessment.Result != Passed || assessment.Attestation == nil || assessment.Attestation.Attester != "alice" {
+			t.Errorf("expected run %d to apply the unexpired attestation, got %s with %+v", i, assessment.Result, assessment.Attestation)
+		}
+	}
+	if len(scheduler.attestations) != 1 {
+		t.Errorf("expected the expired attestation to be dropped, got %+v", scheduler.attestations)
+	}
+}
+
+func TestSchedulerConcurrentRuns(t *testing.T) {
+	scheduler := NewScheduler([]*ControlEvaluation{scheduledEvaluation()}, []string{"Maturity Level 1"}, WithHistorySize(0))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(data bool) {
+			defer wg.Done()
+			if _, err := scheduler.RunOnce(data); err != nil {
+				t.Error(err)
+			}
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	for i, run := range scheduler.History() {
+		if assessment := run.Results.EvaluationSet[0].Assessments[0]; assessment.StepsExecuted != 1 {
+			t.Errorf("expected run %d to execute its step once, got %d", i, assessment.StepsExecuted)
+		}
+	}
+}
@@ -0,0 +1,306 @@
+package layer4
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ossf/gemara/layer2"
+)
+
+// defaultResultValues are the values of each result when computing scores.
+// Results that are not present do not count towards a score.
+var defaultResultValues = map[Result]float64{
+	Passed:      1,
+	NeedsReview: 0.5,
+	Failed:      0,
+	Unknown:     0,
+}
+
+type scoreOpts struct {
+	weights          map[string]float64
+	resultValues     map[Result]float64
+	waivedAsOriginal bool
+}
+
+// ScoreOption defines an option to tune the behavior of the scoring function.
+type ScoreOption func(opts *scoreOpts)
+
+// WithControlWeights is a ScoreOption that sets the weight of controls by their ID.
+// These weights take precedence over weights derived from threat mapping strengths.
+func WithControlWeights(weights map[string]float64) ScoreOption {
+	return func(opts *scoreOpts) {
+		opts.weights = weights
+	}
+}
+
+// WithResultValues is a ScoreOption that replaces the value of each result, between 0 and 1.
+// Results without a value, NotRun, NotApplicable and Waived by default, do not count towards a score.
+// Score returns an error if a value is outside the range.
+func WithResultValues(values map[Result]float64) ScoreOption {
+	return func(opts *scoreOpts) {
+		opts.resultValues = values
+	}
+}
+
+// WithWaivedAsOriginalResult is a ScoreOption that scores waived assessments by the result they had before
+// the waiver was applied, so that waiving a failure does not raise the score.
+func WithWaivedAsOriginalResult() ScoreOption {
+	return func(opts *scoreOpts) {
+		opts.waivedAsOriginal = true
+	}
+}
+
+// Score is a weighted compliance score between 0 and 100 with an explanation of how it was derived.
+type Score struct {
+	// Name identifies what was scored, such as a control ID, family or applicability level
+	Name string `json:"name" yaml:"name"`
+	// Value is the score between 0 and 100. It is zero if nothing could be scored.
+	Value float64 `json:"value" yaml:"value"`
+	// Weight is the total weight of the scored controls
+	Weight float64 `json:"weight" yaml:"weight"`
+	// Scored is the number of assessments that counted towards the score
+	Scored int `json:"scored" yaml:"scored"`
+	// Excluded is the number of assessments that did not count towards the score, such as those not run
+	Excluded int `json:"excluded" yaml:"excluded"`
+	// Explanation describes how the score was derived
+	Explanation string `json:"explanation" yaml:"explanation"`
+}
+
+// HasScore returns true if at least one assessment counted towards the score.
+func (s Score) HasScore() bool {
+	return s.Scored > 0
+}
+
+// ControlScore is the score of a single control evaluation.
+type ControlScore struct {
+	Score `json:",inline" yaml:",inline"`
+	// Family is the control family used to group the control
+	Family string `json:"family" yaml:"family"`
+}
+
+// ScoreReport contains the weighted compliance scores of an evaluation run.
+type ScoreReport struct {
+	// Overall is the weighted mean of all control scores
+	Overall Score `json:"overall" yaml:"overall"`
+	// Controls contains the score of each control, in evaluation order
+	Controls []ControlScore `json:"controls" yaml:"controls"`
+	// Families contains the weighted mean of the control scores in each family, sorted by name
+	Families []Score `json:"families" yaml:"families"`
+	// Applicability contains the weighted mean of the control scores for each applicability level, sorted by name.
+	// Only the assessments with the applicability level count towards its score.
+	Applicability []Score `json:"applicability" yaml:"applicability"`
+}
+
+// Score computes weighted compliance scores per control, control family, applicability level and overall.
+// Each control is scored as the mean value of its assessment results, where Passed is worth 1, NeedsReview 0.5,
+// and Failed and Unknown 0; NotRun, NotApplicable and Waived assessments are excluded. Excluding waived
+// assessments means that waiving a failure raises the score; use WithWaivedAsOriginalResult to score them by
+// their original result, or WithResultValues to give Waived a value. Controls are weighted by the sum of their
+// threat mapping strengths in the Layer 2 catalog, or by WithControlWeights, with a default weight of 1.
+// Families are taken from the catalog as in Summarize. The catalog may be nil.
+// It returns an error if a result value is outside the range 0 to 1.
+func (e *EvaluationResults) Score(catalog *layer2.Catalog, opts ...ScoreOption) (ScoreReport, error) {
+	options := scoreOpts{resultValues: defaultResultValues}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	for _, result := range slices.Sorted(maps.Keys(options.resultValues)) {
+		if value := options.resultValues[result]; value < 0 || value > 1 {
+			return ScoreReport{}, fmt.Errorf("value of result %s must be between 0 and 1, got %g", result, value)
+		}
+	}
+
+	families := make(map[string]string)
+	controls := make(map[string]layer2.Control)
+	if catalog != nil {
+		for _, family := range catalog.ControlFamilies {
+			for _, control := range family.Controls {
+				families[control.Id] = family.Title
+				controls[control.Id] = control
+			}
+		}
+	}
+
+	var report ScoreReport
+	var overall weightedMean
+	familyMeans := make(map[string]*weightedMean)
+	applicabilityMeans := make(map[string]*weightedMean)
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		family, found := families[evaluation.ControlID]
+		if !found {
+			family = familyFromControlId(evaluation.ControlID)
+		}
+		weight, weightSource := controlWeight(evaluation.ControlID, controls, options)
+
+		controlScore := ControlScore{
+			Score:  scoreAssessments(evaluation.ControlID, evaluation.Assessments, "", weight, weightSource, options),
+			Family: family,
+		}
+		report.Controls = append(report.Controls, controlScore)
+		overall.add(controlScore.Score)
+		meanFor(familyMeans, family).add(controlScore.Score)
+
+		for _, applicability := range assessmentApplicability(evaluation.Assessments) {
+			score := scoreAssessments(evaluation.ControlID, evaluation.Assessments, applicability, weight, weightSource, options)
+			meanFor(applicabilityMeans, applicability).add(score)
+		}
+	}
+
+	report.Overall = overall.score("overall", "controls")
+	for _, name := range sortedMeanKeys(familyMeans) {
+		report.Families = append(report.Families, familyMeans[name].score(name, "controls in the family"))
+	}
+	for _, name := range sortedMeanKeys(applicabilityMeans) {
+		report.Applicability = append(report.Applicability, applicabilityMeans[name].score(name, "controls with the applicability level"))
+	}
+	return report, nil
+}
+
+// controlWeight returns the weight of the control and a description of where it came from.
+func controlWeight(controlId string, controls map[string]layer2.Control, options scoreOpts) (float64, string) {
+	if weight, found := options.weights[controlId]; found {
+		return weight, "from the weight table"
+	}
+	var total int64
+	var strengths []string
+	for _, mapping := range controls[controlId].ThreatMappings {
+		for _, entry := range mapping.Entries {
+			total += entry.Strength
+			strengths = append(strengths, fmt.Sprintf("%s: %d", entry.ReferenceId, entry.Strength))
+		}
+	}
+	if total > 0 {
+		return float64(total), fmt.Sprintf("from threat mapping strengths (%s)", strings.Join(strengths, ", "))
+	}
+	return 1, "by default"
+}
+
+// scoreAssessments scores the assessments of a control, optionally only those with the given applicability.
+func scoreAssessments(controlId string, assessments []*Assessment, applicability string, weight float64, weightSource string, options scoreOpts) Score {
+	score := Score{Name: controlId, Weight: weight}
+	var total float64
+	scoredResults := make(map[Result]int)
+	excludedResults := make(map[Result]int)
+	for _, assessment := range assessments {
+		if assessment == nil || (applicability != "" && !slices.Contains(assessment.Applicability, applicability)) {
+			continue
+		}
+		result := assessment.Result
+		if result == Waived && options.waivedAsOriginal && assessment.Waiver != nil {
+			result = assessment.Waiver.OriginalResult
+		}
+		value, found := options.resultValues[result]
+		if !found {
+			score.Excluded++
+			excludedResults[result]++
+			continue
+		}
+		total += value
+		score.Scored++
+		scoredResults[result]++
+	}
+
+	var explanation []string
+	if score.Scored == 0 {
+		score.Weight = 0
+		explanation = append(explanation, "no assessments could be scored")
+	} else {
+		score.Value = 100 * total / float64(score.Scored)
+		var parts []string
+		for _, result := range sortedResults(scoredResults) {
+			parts = append(parts, fmt.Sprintf("%d %s × %g", scoredResults[result], result, options.resultValues[result]))
+		}
+		explanation = append(explanation, fmt.Sprintf("mean of %d assessment results (%s) = %.1f%%", score.Scored, strings.Join(parts, ", "), score.Value))
+		explanation = append(explanation, fmt.Sprintf("weight %g %s", weight, weightSource))
+	}
+	if score.Excluded > 0 {
+		var parts []string
+		for _, result := range sortedResults(excludedResults) {
+			parts = append(parts, fmt.Sprintf("%d %s", excludedResults[result], result))
+		}
+		explanation = append(explanation, fmt.Sprintf("excluded %s", strings.Join(parts, ", ")))
+	}
+	score.Explanation = strings.Join(explanation, "; ")
+	return score
+}
+
+// weightedMean accumulates the scores of controls for a weighted mean.
+type weightedMean struct {
+	total    float64
+	weight   float64
+	scored   int
+	excluded int
+	parts    []string
+}
+
+func (m *weightedMean) add(score Score) {
+	m.scored += score.Scored
+	m.excluded += score.Excluded
+	if !score.HasScore() || score.Weight <= 0 {
+		return
+	}
+	m.total += score.Value * score.Weight
+	m.weight += score.Weight
+	m.parts = append(m.parts, fmt.Sprintf("%s %.1f%% × %g", score.Name, score.Value, score.Weight))
+}
+
+func (m *weightedMean) score(name, description string) Score {
+	score := Score{Name: name, Weight: m.weight, Scored: m.scored, Excluded: m.excluded}
+	if m.weight == 0 {
+		score.Explanation = fmt.Sprintf("none of the %s could be scored", description)
+		return score
+	}
+	score.Value = m.total / m.weight
+	score.Explanation = fmt.Sprintf("weighted mean of %d %s (%s) / total weight %g = %.1f%%",
+		len(m.parts), description, strings.Join(m.parts, ", "), m.weight, score.Value)
+	return score
+}
+
+func meanFor(means map[string]*weightedMean, name string) *weightedMean {
+	if means[name] == nil {
+		means[name] = &weightedMean{}
+	}
+	return means[name]
+}
+
+func sortedMeanKeys(means map[string]*weightedMean) []string {
+	keys := make([]string, 0, len(means))
+	for key := range means {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedResults(counts map[Result]int) []Result {
+	results := make([]Result, 0, len(counts))
+	for result := range counts {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i] < results[j]
+	})
+	return results
+}
+
+// assessmentApplicability returns the distinct applicability levels of the assessments in order of appearance.
+func assessmentApplicability(assessments []*Assessment) []string {
+	var levels []string
+	for _, assessment := range assessments {
+		if assessment == nil {
+			continue
+		}
+		for _, applicability := range assessment.Applicability {
+			if !slices.Contains(levels, applicability) {
+				levels = append(levels, applicability)
+			}
+		}
+	}
+	return levels
+}
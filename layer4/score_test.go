@@ -0,0 +1,149 @@
+package layer4
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/ossf/gemara/layer2"
+)
+
+func scoreResults() *EvaluationResults {
+	return &EvaluationResults{
+		EvaluationSet: []*ControlEvaluation{
+			{
+				ControlID: "OSPS-AC-01",
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-AC-01.01", Applicability: []string{"Maturity Level 1"}, Result: Passed},
+					{RequirementId: "OSPS-AC-01.02", Applicability: []string{"Maturity Level 2"}, Result: Failed},
+				},
+			},
+			{
+				ControlID: "OSPS-BR-01",
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-BR-01.01", Applicability: []string{"Maturity Level 1"}, Result: NeedsReview},
+					{RequirementId: "OSPS-BR-01.02", Applicability: []string{"Maturity Level 1"}, Result: NotRun},
+				},
+			},
+			{
+				ControlID: "OSPS-VM-01",
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-VM-01.01", Applicability: []string{"Maturity Level 2"}, Result: NotApplicable},
+				},
+			},
+		},
+	}
+}
+
+func assertScore(t *testing.T, score Score, want float64) {
+	t.Helper()
+	if math.Abs(score.Value-want) > 0.001 {
+		t.Errorf("expected %s to score %.3f, got %.3f (%s)", score.Name, want, score.Value, score.Explanation)
+	}
+}
+
+func TestScore(t *testing.T) {
+	catalog := sarifCatalog()
+	catalog.ControlFamilies[0].Controls[0].ThreatMappings = []layer2.Mapping{
+		{ReferenceId: "CCC", Entries: []layer2.MappingEntry{{ReferenceId: "TH01", Strength: 2}, {ReferenceId: "TH02", Strength: 1}}},
+	}
+
+	report, err := scoreResults().Score(catalog)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Controls) != 3 {
+		t.Fatalf("expected 3 control scores, got %d", len(report.Controls))
+	}
+
+	accessControl := report.Controls[0]
+	assertScore(t, accessControl.Score, 50)
+	if accessControl.Weight != 3 || accessControl.Family != "Access Control" {
+		t.Errorf("expected weight 3 in the Access Control family, got %v in %q", accessControl.Weight, accessControl.Family)
+	}
+	if !strings.Contains(accessControl.Explanation, "threat mapping strengths (TH01: 2, TH02: 1)") {
+		t.Errorf("expected the explanation to list threat mapping strengths, got %q", accessControl.Explanation)
+	}
+
+	build := report.Controls[1]
+	assertScore(t, build.Score, 50)
+	if build.Scored != 1 || build.Excluded != 1 || !strings.Contains(build.Explanation, "excluded 1 Not Run") {
+		t.Errorf("expected the Not Run assessment to be excluded, got %+v", build.Score)
+	}
+
+	vulnerabilities := report.Controls[2]
+	if vulnerabilities.HasScore() || vulnerabilities.Weight != 0 {
+		t.Errorf("expected a control with only Not Applicable assessments to have no score, got %+v", vulnerabilities.Score)
+	}
+
+	// (50 × 3 + 50 × 1) / 4
+	assertScore(t, report.Overall, 50)
+	if report.Overall.Weight != 4 || report.Overall.Scored != 3 || report.Overall.Excluded != 2 {
+		t.Errorf("unexpected overall score %+v", report.Overall)
+	}
+
+	if len(report.Families) != 3 || report.Families[0].Name != "Access Control" {
+		t.Errorf("expected 3 families starting with Access Control, got %v", report.Families)
+	}
+	if len(report.Applicability) != 2 {
+		t.Fatalf("expected 2 applicability levels, got %v", report.Applicability)
+	}
+	// Level 1: (100 × 3 + 50 × 1) / 4; Level 2: (0 × 3) / 3
+	assertScore(t, report.Applicability[0], 87.5)
+	assertScore(t, report.Applicability[1], 0)
+}
+
+func TestScoreOptions(t *testing.T) {
+	report, err := scoreResults().Score(nil,
+		WithControlWeights(map[string]float64{"OSPS-BR-01": 3}),
+		WithResultValues(map[Result]float64{Passed: 1, NeedsReview: 0, Failed: 0}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Controls[0].Family != "OSPS-AC" || report.Controls[0].Weight != 1 {
+		t.Errorf("expected a default weight without a catalog, got %+v", report.Controls[0])
+	}
+	build := report.Controls[1]
+	assertScore(t, build.Score, 0)
+	if build.Weight != 3 || !strings.Contains(build.Explanation, "weight 3 from the weight table") {
+		t.Errorf("expected the weight table to be used, got %+v", build.Score)
+	}
+	// (50 × 1 + 0 × 3) / 4
+	assertScore(t, report.Overall, 12.5)
+}
+
+func TestScoreInvalidResultValue(t *testing.T) {
+	if _, err := scoreResults().Score(nil, WithResultValues(map[Result]float64{Passed: 2})); err == nil {
+		t.Error("expected an error for a result value above 1")
+	}
+	if _, err := scoreResults().Score(nil, WithResultValues(map[Result]float64{Failed: -1})); err == nil {
+		t.Error("expected an error for a negative result value")
+	}
+}
+
+func TestScoreWaived(t *testing.T) {
+	results := &EvaluationResults{
+		EvaluationSet: []*ControlEvaluation{
+			{
+				ControlID: "OSPS-AC-01",
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-AC-01.01", Result: Passed},
+					{RequirementId: "OSPS-AC-01.02", Result: Waived, Waiver: &AssessmentWaiver{OriginalResult: Failed}},
+				},
+			},
+		},
+	}
+	report, err := results.Score(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertScore(t, report.Overall, 100)
+
+	report, err = results.Score(nil, WithWaivedAsOriginalResult())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertScore(t, report.Overall, 50)
+}
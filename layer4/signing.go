@@ -0,0 +1,145 @@
+package layer4
+
+import (
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ossf/gemara/dsse"
+	"github.com/ossf/gemara/layer2"
+)
+
+// EvaluationPredicateType is the in-toto predicate type of signed evaluation results.
+const EvaluationPredicateType = "https://github.com/ossf/gemara/layer4/evaluation-results/v1"
+
+// ResultsSubjectName is the name of the default subject of signed evaluation results,
+// whose digest is the SHA-256 digest of the results in the predicate.
+const ResultsSubjectName = "evaluation-results"
+
+// EvaluationPredicate is the predicate of a signed in-toto statement about an evaluation run.
+type EvaluationPredicate struct {
+	// CatalogId is the ID of the Layer 2 catalog the controls were evaluated against
+	CatalogId string `json:"catalog-id"`
+	// CatalogVersion is the version of the Layer 2 catalog
+	CatalogVersion string `json:"catalog-version,omitempty"`
+	// Results are the signed evaluation results
+	Results *EvaluationResults `json:"results"`
+}
+
+// signedPredicate is the serialized form of an EvaluationPredicate, keeping the exact bytes of the results
+// so that their digest can be checked.
+type signedPredicate struct {
+	CatalogId      string          `json:"catalog-id"`
+	CatalogVersion string          `json:"catalog-version,omitempty"`
+	Results        json.RawMessage `json:"results"`
+}
+
+type signOpts struct {
+	keyId    string
+	subjects []dsse.Subject
+}
+
+// SignOption defines an option to tune the signed envelope.
+type SignOption func(opts *signOpts)
+
+// WithKeyId is a SignOption that records the ID of the signing key in the envelope.
+func WithKeyId(keyId string) SignOption {
+	return func(opts *signOpts) {
+		opts.keyId = keyId
+	}
+}
+
+// WithSubject is a SignOption that adds a subject to the statement, such as the evaluated repository or
+// release artefact, identified by its digests (e.g. {"sha256": "..."} or {"gitCommit": "..."}).
+func WithSubject(name string, digest map[string]string) SignOption {
+	return func(opts *signOpts) {
+		opts.subjects = append(opts.subjects, dsse.Subject{Name: name, Digest: digest})
+	}
+}
+
+// Sign creates a DSSE envelope containing an in-toto statement about the evaluation results, signed with a
+// locally supplied Ed25519, ECDSA or RSA key. The predicate records the ID and version of the evaluated catalog.
+// The statement's subjects always include the results themselves, so that they can be checked independently.
+func (e *EvaluationResults) Sign(catalog *layer2.Catalog, signer crypto.Signer, opts ...SignOption) (dsse.Envelope, error) {
+	if catalog == nil || catalog.Metadata.Id == "" {
+		return dsse.Envelope{}, errors.New("a catalog with an ID is required to sign evaluation results")
+	}
+	var options signOpts
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	results, err := json.Marshal(e)
+	if err != nil {
+		return dsse.Envelope{}, fmt.Errorf("error encoding evaluation results: %w", err)
+	}
+	statement := dsse.Statement{
+		Type:          dsse.StatementType,
+		Subject:       append([]dsse.Subject{{Name: ResultsSubjectName, Digest: sha256Digest(results)}}, options.subjects...),
+		PredicateType: EvaluationPredicateType,
+		Predicate: signedPredicate{
+			CatalogId:      catalog.Metadata.Id,
+			CatalogVersion: catalog.Metadata.Version,
+			Results:        results,
+		},
+	}
+	payload, err := json.Marshal(statement)
+	if err != nil {
+		return dsse.Envelope{}, fmt.Errorf("error encoding statement: %w", err)
+	}
+	return dsse.Sign(dsse.InTotoPayloadType, payload, signer, options.keyId)
+}
+
+// VerifyEvaluationResults checks that the envelope was signed by one of the public keys and contains an
+// unmodified evaluation results statement, and returns its predicate. No network access is required.
+func VerifyEvaluationResults(envelope dsse.Envelope, keys ...crypto.PublicKey) (*EvaluationPredicate, error) {
+	if envelope.PayloadType != dsse.InTotoPayloadType {
+		return nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+	payload, err := envelope.Verify(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	var statement struct {
+		dsse.Statement
+		Predicate signedPredicate `json:"predicate"`
+	}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("error decoding statement: %w", err)
+	}
+	if statement.Type != dsse.StatementType || statement.PredicateType != EvaluationPredicateType {
+		return nil, fmt.Errorf("unexpected statement type %q with predicate type %q", statement.Type, statement.PredicateType)
+	}
+	digest := sha256Digest(statement.Predicate.Results)
+	verified := false
+	for _, subject := range statement.Subject {
+		if subject.Name == ResultsSubjectName {
+			if subject.Digest["sha256"] != digest["sha256"] {
+				return nil, errors.New("evaluation results do not match the digest of the statement subject")
+			}
+			verified = true
+		}
+	}
+	if !verified {
+		return nil, fmt.Errorf("statement does not have a %s subject", ResultsSubjectName)
+	}
+
+	predicate := &EvaluationPredicate{
+		CatalogId:      statement.Predicate.CatalogId,
+		CatalogVersion: statement.Predicate.CatalogVersion,
+		Results:        &EvaluationResults{},
+	}
+	if err := json.Unmarshal(statement.Predicate.Results, predicate.Results); err != nil {
+		return nil, fmt.Errorf("error decoding evaluation results: %w", err)
+	}
+	return predicate, nil
+}
+
+func sha256Digest(data []byte) map[string]string {
+	sum := sha256.Sum256(data)
+	return map[string]string{"sha256": hex.EncodeToString(sum[:])}
+}
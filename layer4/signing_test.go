@@ -0,0 +1,77 @@
+package layer4
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ossf/gemara/dsse"
+	"github.com/ossf/gemara/layer2"
+)
+
+func TestSignEvaluationResults(t *testing.T) {
+	results := &EvaluationResults{}
+	if err := results.LoadFile("./test-data/pvtr-baseline-scan.yaml"); err != nil {
+		t.Fatal(err)
+	}
+	catalog := &layer2.Catalog{Metadata: layer2.Metadata{Id: "OSPS-B", Version: "2025.02.25"}}
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+
+	envelope, err := results.Sign(catalog, private, WithKeyId("pipeline"), WithSubject("git+https://github.com/ossf/gemara", map[string]string{"gitCommit": "c4b90c7"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Signatures[0].KeyId != "pipeline" {
+		t.Errorf("expected the key ID to be recorded, got %q", envelope.Signatures[0].KeyId)
+	}
+
+	predicate, err := VerifyEvaluationResults(envelope, public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if predicate.CatalogId != "OSPS-B" || predicate.CatalogVersion != "2025.02.25" {
+		t.Errorf("unexpected predicate catalog %s %s", predicate.CatalogId, predicate.CatalogVersion)
+	}
+	if len(predicate.Results.EvaluationSet) != len(results.EvaluationSet) {
+		t.Errorf("expected %d control evaluations, got %d", len(results.EvaluationSet), len(predicate.Results.EvaluationSet))
+	}
+
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := VerifyEvaluationResults(envelope, other.Public()); err == nil {
+		t.Error("expected verification with another key to fail")
+	}
+
+	payload, _ := base64.StdEncoding.DecodeString(envelope.Payload)
+	tampered := envelope
+	tampered.Payload = base64.StdEncoding.EncodeToString([]byte(strings.Replace(string(payload), `"Passed"`, `"Failed"`, 1)))
+	if _, err := VerifyEvaluationResults(tampered, public); err == nil {
+		t.Error("expected verification of modified results to fail")
+	}
+
+	if _, err := results.Sign(nil, private); err == nil {
+		t.Error("expected an error without a catalog")
+	}
+}
+
+func TestVerifyEvaluationResultsSubject(t *testing.T) {
+	public, private, _ := ed25519.GenerateKey(rand.Reader)
+	statement := dsse.Statement{
+		Type:          dsse.StatementType,
+		Subject:       []dsse.Subject{{Name: ResultsSubjectName, Digest: map[string]string{"sha256": "0000"}}},
+		PredicateType: EvaluationPredicateType,
+		Predicate:     EvaluationPredicate{CatalogId: "OSPS-B", Results: &EvaluationResults{}},
+	}
+	payload, _ := json.Marshal(statement)
+	envelope, err := dsse.Sign(dsse.InTotoPayloadType, payload, private, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyEvaluationResults(envelope, public); err == nil || !strings.Contains(err.Error(), "digest") {
+		t.Errorf("expected a digest mismatch, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package layer4
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// StepInfo identifies an assessment step independently of its function name, so that results remain comparable
+// when steps are renamed or moved. Registered steps are recorded in results by their ID.
+type StepInfo struct {
+	// Id is the stable, unique identifier of the step
+	Id string `json:"id" yaml:"id"`
+	// Description is a human-readable description of what the step checks
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// Version is the version of the step's logic, changed when the step may produce different results
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+}
+
+// stepQuery is passed to the wrappers created by NewStep and TypedStep.Step to retrieve the identity of the wrapped step.
+type stepQuery struct {
+	name string
+	info *StepInfo
+}
+
+// closureName matches the function names generated for anonymous functions, which are not stable.
+var closureName = regexp.MustCompile(`\.func\d+(\.\d+)*$`)
+
+var stepRegistry = struct {
+	sync.RWMutex
+	byName map[string]StepInfo
+	byId   map[string]string
+}{
+	byName: make(map[string]StepInfo),
+	byId:   make(map[string]string),
+}
+
+// RegisterStep registers the ID, description and version of a step function, which are used instead of its
+// function name in results. Anonymous functions cannot be registered, as their names are not unique; use NewStep
+// to give them an identity instead. Registering the same function with the same info again has no effect.
+func RegisterStep(step AssessmentStep, info StepInfo) error {
+	if info.Id == "" {
+		return errors.New("a step must be registered with an ID")
+	}
+	name, explicit := step.identity()
+	if explicit != nil {
+		return fmt.Errorf("step %s already has the ID %s", name, explicit.Id)
+	}
+	if closureName.MatchString(name) {
+		return fmt.Errorf("anonymous function %s cannot be registered, use NewStep instead", name)
+	}
+
+	stepRegistry.Lock()
+	defer stepRegistry.Unlock()
+	if registered, found := stepRegistry.byName[name]; found {
+		if registered == info {
+			return nil
+		}
+		return fmt.Errorf("step %s is already registered with the ID %s", name, registered.Id)
+	}
+	if registered, found := stepRegistry.byId[info.Id]; found {
+		return fmt.Errorf("step ID %s is already registered for %s", info.Id, registered)
+	}
+	stepRegistry.byName[name] = info
+	stepRegistry.byId[info.Id] = name
+	return nil
+}
+
+// NewStep returns an AssessmentStep that runs the step and is identified by the info, which may be used for
+// anonymous functions and steps created at runtime. If the info has no ID, the step keeps its own identity.
+func NewStep(info StepInfo, step AssessmentStep) AssessmentStep {
+	return func(payload interface{}, changes map[string]*Change) (Result, string) {
+		if query, ok := payload.(*stepQuery); ok {
+			query.name, query.info = step.identity()
+			if info.Id != "" {
+				query.info = &info
+			}
+			return NotRun, ""
+		}
+		return step(payload, changes)
+	}
+}
+
+// namedStepWrapper is the function name shared by every AssessmentStep created by NewStep.
+// It is set in init, as the wrapper refers to it when looking through the wrapped step.
+var namedStepWrapper string
+
+func init() {
+	namedStepWrapper = functionName(NewStep(StepInfo{}, nil))
+}
+
+// identity returns the function name of the step, looking through the wrappers created by NewStep and
+// TypedStep.Step, and the info given to it with NewStep, if any.
+func (as AssessmentStep) identity() (name string, explicit *StepInfo) {
+	name = functionName(as)
+	if name == namedStepWrapper || name == typedStepWrapper {
+		query := &stepQuery{}
+		as(query, nil)
+		return query.name, query.info
+	}
+	return name, nil
+}
+
+// resolve returns the function name of the step and its explicit or registered info, if any.
+func (as AssessmentStep) resolve() (name string, info *StepInfo) {
+	name, info = as.identity()
+	if info == nil {
+		stepRegistry.RLock()
+		registered, found := stepRegistry.byName[name]
+		stepRegistry.RUnlock()
+		if found {
+			info = &registered
+		}
+	}
+	return name, info
+}
+
+// Info returns the registered or explicit info of the step, and false if the step has none.
+func (as AssessmentStep) Info() (StepInfo, bool) {
+	_, info := as.resolve()
+	if info == nil {
+		return StepInfo{}, false
+	}
+	return *info, true
+}
+
+// stepDetails returns the info of the steps that have an ID.
+func stepDetails(steps []AssessmentStep) []StepInfo {
+	var details []StepInfo
+	for _, step := range steps {
+		if info, found := step.Info(); found {
+			details = append(details, info)
+		}
+	}
+	return details
+}
@@ -0,0 +1,109 @@
+package layer4
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func orgRequiresMFA(interface{}, map[string]*Change) (Result, string) {
+	return Passed, "MFA is required"
+}
+
+func branchProtected(interface{}, map[string]*Change) (Result, string) {
+	return Passed, "branch is protected"
+}
+
+func typedBranchProtected(*repository, map[string]*Change) (Result, string) {
+	return Passed, "branch is protected"
+}
+
+func TestRegisterStep(t *testing.T) {
+	info := StepInfo{Id: "org-requires-mfa", Description: "Checks that the organization requires MFA", Version: "1.0.0"}
+	if err := RegisterStep(orgRequiresMFA, info); err != nil {
+		t.Fatal(err)
+	}
+	if err := RegisterStep(orgRequiresMFA, info); err != nil {
+		t.Errorf("expected registering the same step again to have no effect, got %v", err)
+	}
+	if err := RegisterStep(orgRequiresMFA, StepInfo{Id: "mfa"}); err == nil {
+		t.Error("expected an error when registering a step with a different ID")
+	}
+	if err := RegisterStep(branchProtected, StepInfo{Id: "org-requires-mfa"}); err == nil {
+		t.Error("expected an error when registering an ID that is already used")
+	}
+	if err := RegisterStep(branchProtected, StepInfo{}); err == nil {
+		t.Error("expected an error when registering a step without an ID")
+	}
+	closure := func(interface{}, map[string]*Change) (Result, string) { return Passed, "" }
+	if err := RegisterStep(closure, StepInfo{Id: "closure"}); err == nil {
+		t.Error("expected an error when registering an anonymous function")
+	}
+	if err := RegisterStep(TypedStep[*repository](typedBranchProtected).Step(), StepInfo{Id: "typed-branch-protected"}); err != nil {
+		t.Errorf("expected typed steps to be registered by their function, got %v", err)
+	}
+
+	if name := AssessmentStep(orgRequiresMFA).String(); name != "org-requires-mfa" {
+		t.Errorf("expected registered step to be named by its ID, got %s", name)
+	}
+	if name := TypedStep[*repository](typedBranchProtected).Step().String(); name != "typed-branch-protected" {
+		t.Errorf("expected registered typed step to be named by its ID, got %s", name)
+	}
+	if name := AssessmentStep(branchProtected).String(); !strings.HasSuffix(name, "layer4.branchProtected") {
+		t.Errorf("expected unregistered step to fall back to its function name, got %s", name)
+	}
+	if _, found := AssessmentStep(branchProtected).Info(); found {
+		t.Error("expected unregistered step to have no info")
+	}
+}
+
+func TestNewStep(t *testing.T) {
+	step := NewStep(StepInfo{Id: "branch-protected", Version: "2"}, func(interface{}, map[string]*Change) (Result, string) {
+		return Failed, "branch is not protected"
+	})
+	if result, message := step(nil, nil); result != Failed || message != "branch is not protected" {
+		t.Errorf("expected the wrapped step to run, got %s: %s", result, message)
+	}
+	if info, found := step.Info(); !found || info.Id != "branch-protected" || info.Version != "2" {
+		t.Errorf("expected explicit info, got %+v", info)
+	}
+	if err := RegisterStep(step, StepInfo{Id: "other"}); err == nil {
+		t.Error("expected an error when registering a step created with NewStep")
+	}
+	if name := NewStep(StepInfo{}, branchProtected).String(); !strings.HasSuffix(name, "layer4.branchProtected") {
+		t.Errorf("expected a step without an ID to keep its own name, got %s", name)
+	}
+}
+
+func TestStepDetailsRoundTrip(t *testing.T) {
+	if err := RegisterStep(orgRequiresMFA, StepInfo{Id: "org-requires-mfa", Description: "Checks that the organization requires MFA", Version: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	assessment, err := NewAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []AssessmentStep{
+		orgRequiresMFA,
+		NewStep(StepInfo{Id: "branch-protected", Version: "2"}, branchProtected),
+		branchProtected,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(assessment.StepDetails) != 2 || assessment.StepDetails[0].Id != "org-requires-mfa" {
+		t.Errorf("expected the details of the identified steps, got %+v", assessment.StepDetails)
+	}
+	data, err := json.Marshal(assessment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded Assessment
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	names := loaded.StepNames()
+	if len(names) != 3 || names[0] != "org-requires-mfa" || names[1] != "branch-protected" || !strings.HasSuffix(names[2], "layer4.branchProtected") {
+		t.Errorf("expected step IDs with a fallback name, got %v", names)
+	}
+	details := loaded.StepDetails
+	if len(details) != 2 || details[0].Version != "1.0.0" || details[1].Id != "branch-protected" {
+		t.Errorf("expected step details to survive a round trip, got %+v", details)
+	}
+}
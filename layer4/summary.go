@@ -0,0 +1,173 @@
+package layer4
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/ossf/gemara/layer2"
+)
+
+// ResultCounts contains the number of assessments with each result.
+type ResultCounts struct {
+	Passed        int `json:"passed" yaml:"passed"`
+	Failed        int `json:"failed" yaml:"failed"`
+	NeedsReview   int `json:"needs-review" yaml:"needs-review"`
+	NotApplicable int `json:"not-applicable" yaml:"not-applicable"`
+	NotRun        int `json:"not-run" yaml:"not-run"`
+	Unknown       int `json:"unknown" yaml:"unknown"`
+	Waived        int `json:"waived,omitempty" yaml:"waived,omitempty"`
+}
+
+// Total returns the number of assessments counted.
+func (r ResultCounts) Total() int {
+	return r.Passed + r.Failed + r.NeedsReview + r.NotApplicable + r.NotRun + r.Unknown + r.Waived
+}
+
+func (r *ResultCounts) add(result Result) {
+	switch result {
+	case Passed:
+		r.Passed++
+	case Failed:
+		r.Failed++
+	case NeedsReview:
+		r.NeedsReview++
+	case NotApplicable:
+		r.NotApplicable++
+	case NotRun:
+		r.NotRun++
+	case Waived:
+		r.Waived++
+	default:
+		r.Unknown++
+	}
+}
+
+// GroupSummary contains the result counts for a group of assessments, such as a control family.
+type GroupSummary struct {
+	Name   string       `json:"name" yaml:"name"`
+	Counts ResultCounts `json:"counts" yaml:"counts"`
+}
+
+// FailingAssessment describes a failed assessment for reporting.
+type FailingAssessment struct {
+	ControlId      string `json:"control-id" yaml:"control-id"`
+	Family         string `json:"family" yaml:"family"`
+	RequirementId  string `json:"requirement-id" yaml:"requirement-id"`
+	Message        string `json:"message" yaml:"message"`
+	Recommendation string `json:"recommendation,omitempty" yaml:"recommendation,omitempty"`
+
+	// redactor removes secrets from the message when the assessment is serialized
+	redactor *Redactor
+}
+
+// failingAssessmentFields is the serialized form of a FailingAssessment.
+type failingAssessmentFields FailingAssessment
+
+// MarshalYAML serializes the failing assessment with secrets redacted from its message
+func (f FailingAssessment) MarshalYAML() (interface{}, error) {
+	fields := failingAssessmentFields(f)
+	fields.Message = f.RedactedMessage()
+	return fields, nil
+}
+
+// MarshalJSON serializes the failing assessment with secrets redacted from its message
+func (f FailingAssessment) MarshalJSON() ([]byte, error) {
+	fields := failingAssessmentFields(f)
+	fields.Message = f.RedactedMessage()
+	return json.Marshal(fields)
+}
+
+// RedactedMessage returns the message with secrets redacted, for reports that do not serialize the assessment.
+func (f FailingAssessment) RedactedMessage() string {
+	return f.redactor.RedactString("message", f.Message)
+}
+
+// Summary contains assessment result counts for an evaluation run, grouped by control family
+// and by applicability, along with the assessments that failed.
+type Summary struct {
+	// Counts contains the result counts for all assessments
+	Counts ResultCounts `json:"counts" yaml:"counts"`
+	// Families contains the result counts per control family, sorted by name
+	Families []GroupSummary `json:"families" yaml:"families"`
+	// Applicability contains the result counts per applicability level, sorted by name.
+	// Assessments with multiple applicability levels are counted for each of them.
+	Applicability []GroupSummary `json:"applicability" yaml:"applicability"`
+	// Failing lists the failed assessments in the order they were evaluated
+	Failing []FailingAssessment `json:"failing,omitempty" yaml:"failing,omitempty"`
+}
+
+// Summarize counts the assessment results of the evaluation run. Controls are grouped by the title
+// of their family in the Layer 2 catalog. If the catalog is nil or does not contain the control,
+// the family is derived from the control ID by removing its last segment, e.g. OSPS-AC-01 becomes OSPS-AC.
+func (e *EvaluationResults) Summarize(catalog *layer2.Catalog) Summary {
+	families := make(map[string]string)
+	if catalog != nil {
+		for _, family := range catalog.ControlFamilies {
+			for _, control := range family.Controls {
+				families[control.Id] = family.Title
+			}
+		}
+	}
+
+	var summary Summary
+	familyCounts := make(map[string]*ResultCounts)
+	applicabilityCounts := make(map[string]*ResultCounts)
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		family, found := families[evaluation.ControlID]
+		if !found {
+			family = familyFromControlId(evaluation.ControlID)
+		}
+		for _, assessment := range evaluation.Assessments {
+			if assessment == nil {
+				continue
+			}
+			summary.Counts.add(assessment.Result)
+			countFor(familyCounts, family).add(assessment.Result)
+			for _, applicability := range assessment.Applicability {
+				countFor(applicabilityCounts, applicability).add(assessment.Result)
+			}
+			if assessment.Result == Failed {
+				summary.Failing = append(summary.Failing, FailingAssessment{
+					ControlId:      evaluation.ControlID,
+					Family:         family,
+					RequirementId:  assessment.RequirementId,
+					Message:        assessment.Message,
+					Recommendation: assessment.Recommendation,
+					redactor:       assessment.redactor,
+				})
+			}
+		}
+	}
+	summary.Families = groupSummaries(familyCounts)
+	summary.Applicability = groupSummaries(applicabilityCounts)
+	return summary
+}
+
+func countFor(counts map[string]*ResultCounts, name string) *ResultCounts {
+	if counts[name] == nil {
+		counts[name] = &ResultCounts{}
+	}
+	return counts[name]
+}
+
+func groupSummaries(counts map[string]*ResultCounts) []GroupSummary {
+	groups := make([]GroupSummary, 0, len(counts))
+	for name, count := range counts {
+		groups = append(groups, GroupSummary{Name: name, Counts: *count})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
+
+func familyFromControlId(controlId string) string {
+	if i := strings.LastIndexAny(controlId, "-."); i > 0 {
+		return controlId[:i]
+	}
+	return controlId
+}
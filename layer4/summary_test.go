@@ -0,0 +1,44 @@
+package layer4
+
+import (
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	results := &EvaluationResults{}
+	if err := results.LoadFile("./test-data/pvtr-baseline-scan.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	summary := results.Summarize(nil)
+	if summary.Counts.Total() != 54 {
+		t.Errorf("expected 54 assessments, got %d", summary.Counts.Total())
+	}
+	if summary.Counts.Failed != len(summary.Failing) {
+		t.Errorf("expected %d failing assessments, got %d", summary.Counts.Failed, len(summary.Failing))
+	}
+	if len(summary.Families) != 8 || summary.Families[0].Name != "OSPS-AC" {
+		t.Errorf("expected 8 families starting with OSPS-AC, got %v", summary.Families)
+	}
+	if len(summary.Applicability) != 3 || summary.Applicability[0].Name != "Maturity Level 1" || summary.Applicability[0].Counts.Total() != 21 {
+		t.Errorf("expected 21 assessments for Maturity Level 1, got %v", summary.Applicability)
+	}
+
+	summary = results.Summarize(sarifCatalog())
+	if summary.Families[0].Name != "Access Control" || summary.Families[0].Counts.Total() != 1 {
+		t.Errorf("expected the catalog family title to be used, got %v", summary.Families[0])
+	}
+}
+
+func TestFamilyFromControlId(t *testing.T) {
+	tests := map[string]string{
+		"OSPS-AC-01": "OSPS-AC",
+		"CCC.C01":    "CCC",
+		"CTRL":       "CTRL",
+	}
+	for controlId, want := range tests {
+		if got := familyFromControlId(controlId); got != want {
+			t.Errorf("familyFromControlId(%q) = %q, want %q", controlId, got, want)
+		}
+	}
+}
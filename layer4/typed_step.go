@@ -0,0 +1,58 @@
+package layer4
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// TypedStep is an assessment step over a concrete target type, so that the target data is type-checked at compile
+// time instead of being asserted by each step.
+type TypedStep[T any] func(target T, changes map[string]*Change) (Result, string)
+
+// typedStepWrapper is the function name shared by every AssessmentStep created by TypedStep.Step.
+var typedStepWrapper = functionName(TypedStep[struct{}](nil).Step())
+
+func functionName(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "<unknown function>"
+	}
+	return f.Name()
+}
+
+// Step converts the typed step to an AssessmentStep that can be used with any Assessment.
+// The AssessmentStep returns Unknown if the target data is not of type T, and is named after the typed step.
+func (s TypedStep[T]) Step() AssessmentStep {
+	return func(payload interface{}, changes map[string]*Change) (Result, string) {
+		if query, ok := payload.(*stepQuery); ok {
+			query.name = functionName(s)
+			return NotRun, ""
+		}
+		target, ok := payload.(T)
+		if !ok {
+			return Unknown, fmt.Sprintf("expected target data of type %s, got %T", reflect.TypeFor[T](), payload)
+		}
+		return s(target, changes)
+	}
+}
+
+// TypedSteps converts typed steps to AssessmentSteps.
+func TypedSteps[T any](steps ...TypedStep[T]) []AssessmentStep {
+	converted := make([]AssessmentStep, 0, len(steps))
+	for _, step := range steps {
+		converted = append(converted, step.Step())
+	}
+	return converted
+}
+
+// AddTypedAssessment creates a new Assessment with typed steps and adds it to the ControlEvaluation.
+func AddTypedAssessment[T any](c *ControlEvaluation, requirementId string, description string, applicability []string, steps ...TypedStep[T]) *Assessment {
+	return c.AddAssessment(requirementId, description, applicability, TypedSteps(steps...))
+}
+
+// EvaluateTyped evaluates the control against target data of type T, which is checked at compile time.
+// It is equivalent to calling Evaluate on the control evaluation.
+func EvaluateTyped[T any](c *ControlEvaluation, targetData T, userApplicability []string, changesAllowed bool, opts ...RunOption) {
+	c.Evaluate(targetData, userApplicability, changesAllowed, opts...)
+}
@@ -0,0 +1,63 @@
+package layer4
+
+import (
+	"strings"
+	"testing"
+)
+
+type repository struct {
+	Name          string
+	MFARequired   bool
+	DefaultBranch string
+}
+
+func mfaRequired(repo *repository, _ map[string]*Change) (Result, string) {
+	if repo.MFARequired {
+		return Passed, repo.Name + " requires MFA"
+	}
+	return Failed, repo.Name + " does not require MFA"
+}
+
+func defaultBranch(repo repository, _ map[string]*Change) (Result, string) {
+	if repo.DefaultBranch == "main" {
+		return Passed, "default branch is main"
+	}
+	return NeedsReview, "default branch is " + repo.DefaultBranch
+}
+
+func TestTypedSteps(t *testing.T) {
+	evaluation := &ControlEvaluation{ControlID: "OSPS-AC-01"}
+	typed := AddTypedAssessment(evaluation, "OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, mfaRequired)
+	// Typed steps can be mixed with existing steps
+	mixed := evaluation.AddAssessment("OSPS-AC-01.02", "Check MFA", []string{"Maturity Level 1"},
+		append(TypedSteps[*repository](mfaRequired), TypedStep[*repository](mfaRequired).Step()))
+
+	EvaluateTyped(evaluation, &repository{Name: "gemara", MFARequired: true}, []string{"Maturity Level 1"}, false)
+
+	if typed.Result != Passed || typed.Message != "gemara requires MFA" || mixed.Result != Passed {
+		t.Errorf("expected typed steps to pass, got %s: %s and %s", typed.Result, typed.Message, mixed.Result)
+	}
+	for _, name := range mixed.StepNames() {
+		if !strings.HasSuffix(name, "layer4.mfaRequired") {
+			t.Errorf("expected typed steps to be named after their function, got %s", name)
+		}
+	}
+}
+
+func TestTypedStepWrongPayload(t *testing.T) {
+	step := TypedStep[repository](defaultBranch).Step()
+	result, message := step(&repository{}, nil)
+	if result != Unknown || !strings.Contains(message, "layer4.repository") || !strings.Contains(message, "*layer4.repository") {
+		t.Errorf("expected Unknown for a payload of the wrong type, got %s: %s", result, message)
+	}
+	result, _ = step(nil, nil)
+	if result != Unknown {
+		t.Errorf("expected Unknown for a nil payload, got %s", result)
+	}
+	if result, message := step(repository{DefaultBranch: "master"}, nil); result != NeedsReview || message != "default branch is master" {
+		t.Errorf("expected the typed step to run, got %s: %s", result, message)
+	}
+	if name := step.String(); !strings.HasSuffix(name, "layer4.defaultBranch") {
+		t.Errorf("expected typed step to be named after its function, got %s", name)
+	}
+}
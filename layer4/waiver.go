@@ -0,0 +1,171 @@
+package layer4
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ossf/gemara/layer3"
+)
+
+// AssessmentWaiver records the waiver that was applied to an assessment result.
+type AssessmentWaiver struct {
+	// WaiverId is the ID of the Layer 3 waiver
+	WaiverId string `json:"waiver-id" yaml:"waiver-id"`
+	// Justification is the reason the risk was accepted
+	Justification string `json:"justification" yaml:"justification"`
+	// Approver is the name of the contact who approved the waiver
+	Approver string `json:"approver" yaml:"approver"`
+	// Expires is the time the waiver expires
+	Expires string `json:"expires" yaml:"expires"`
+	// Expired is true if the waiver had expired when it was applied, in which case the result was not changed
+	Expired bool `json:"expired" yaml:"expired"`
+	// OriginalResult is the result of the assessment before the waiver was applied
+	OriginalResult Result `json:"original-result" yaml:"original-result"`
+}
+
+// WaiverMatch identifies an assessment covered by a waiver.
+type WaiverMatch struct {
+	WaiverId      string `json:"waiver-id" yaml:"waiver-id"`
+	ControlId     string `json:"control-id" yaml:"control-id"`
+	RequirementId string `json:"requirement-id" yaml:"requirement-id"`
+	Expires       string `json:"expires" yaml:"expires"`
+}
+
+// WaiverReport describes the outcome of applying waivers to evaluation results.
+type WaiverReport struct {
+	// Waived lists the failing assessments that were re-labelled as Waived
+	Waived []WaiverMatch `json:"waived" yaml:"waived"`
+	// Expired lists the failing assessments that were only covered by expired waivers
+	Expired []WaiverMatch `json:"expired" yaml:"expired"`
+	// Unused lists the IDs of waivers that did not cover any failing assessment
+	Unused []string `json:"unused" yaml:"unused"`
+}
+
+type waiverOpts struct {
+	target string
+	now    time.Time
+}
+
+// WaiverOption defines an option to tune the behavior of ApplyWaivers.
+type WaiverOption func(opts *waiverOpts)
+
+// WithWaiverTarget is a WaiverOption that sets the name of the evaluated target.
+// Waivers that name a target only apply when it matches; by default, only waivers without a target apply.
+func WithWaiverTarget(target string) WaiverOption {
+	return func(opts *waiverOpts) {
+		opts.target = target
+	}
+}
+
+// WithWaiverTime is a WaiverOption that sets the time used to check waiver expiry, instead of the current time.
+func WithWaiverTime(now time.Time) WaiverOption {
+	return func(opts *waiverOpts) {
+		opts.now = now
+	}
+}
+
+// ApplyWaivers overlays Layer 3 waivers on the evaluation results. Failed assessments covered by a waiver that
+// has not expired are re-labelled as Waived, keeping the original result in the assessment's Waiver record.
+// Failed assessments covered only by expired waivers keep their result, and the expired waiver is recorded.
+// Control results are recalculated from their assessments. Applying waivers again replaces earlier waivers,
+// so results can be re-evaluated as waivers expire.
+func (e *EvaluationResults) ApplyWaivers(waivers []layer3.Waiver, opts ...WaiverOption) (WaiverReport, error) {
+	options := waiverOpts{now: time.Now()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	expiries := make([]time.Time, len(waivers))
+	for i, waiver := range waivers {
+		expires, err := time.Parse(time.RFC3339, string(waiver.Expires))
+		if err != nil {
+			return WaiverReport{}, fmt.Errorf("waiver %s has an invalid expiry: %w", waiver.Id, err)
+		}
+		expiries[i] = expires
+	}
+
+	report := WaiverReport{Waived: []WaiverMatch{}, Expired: []WaiverMatch{}, Unused: []string{}}
+	used := make(map[string]bool)
+	for _, evaluation := range e.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		changed := false
+		for _, assessment := range evaluation.Assessments {
+			if assessment == nil {
+				continue
+			}
+			if assessment.Waiver != nil {
+				if !assessment.Waiver.Expired {
+					assessment.Result = assessment.Waiver.OriginalResult
+				}
+				assessment.Waiver = nil
+				changed = true
+			}
+			if assessment.Result != Failed {
+				continue
+			}
+
+			index := matchWaiver(waivers, expiries, evaluation.ControlID, assessment.RequirementId, options)
+			if index < 0 {
+				continue
+			}
+			waiver := waivers[index]
+			used[waiver.Id] = true
+			expired := !expiries[index].After(options.now)
+			assessment.Waiver = &AssessmentWaiver{
+				WaiverId:       waiver.Id,
+				Justification:  waiver.Justification,
+				Approver:       waiver.Approver.Name,
+				Expires:        string(waiver.Expires),
+				Expired:        expired,
+				OriginalResult: assessment.Result,
+			}
+			match := WaiverMatch{
+				WaiverId:      waiver.Id,
+				ControlId:     evaluation.ControlID,
+				RequirementId: assessment.RequirementId,
+				Expires:       string(waiver.Expires),
+			}
+			if expired {
+				report.Expired = append(report.Expired, match)
+				continue
+			}
+			assessment.Result = Waived
+			report.Waived = append(report.Waived, match)
+			changed = true
+		}
+		if changed {
+			evaluation.aggregateAssessments()
+		}
+	}
+
+	for _, waiver := range waivers {
+		if !used[waiver.Id] {
+			report.Unused = append(report.Unused, waiver.Id)
+		}
+	}
+	return report, nil
+}
+
+// matchWaiver returns the index of the waiver covering the assessment, preferring waivers that have not expired.
+// It returns -1 if no waiver covers the assessment.
+func matchWaiver(waivers []layer3.Waiver, expiries []time.Time, controlId, requirementId string, options waiverOpts) int {
+	match := -1
+	for i, waiver := range waivers {
+		if waiver.ControlId != controlId || (waiver.Target != "" && waiver.Target != options.target) {
+			continue
+		}
+		if len(waiver.RequirementIds) > 0 && !slices.Contains(waiver.RequirementIds, requirementId) {
+			continue
+		}
+		if expiries[i].After(options.now) {
+			return i
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	return match
+}
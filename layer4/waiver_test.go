@@ -0,0 +1,160 @@
+package layer4
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+
+	"github.com/ossf/gemara/layer3"
+)
+
+func waiverResults() *EvaluationResults {
+	return &EvaluationResults{
+		EvaluationSet: []*ControlEvaluation{
+			{
+				ControlID: "OSPS-AC-01",
+				Result:    Failed,
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-AC-01.01", Result: Failed},
+					{RequirementId: "OSPS-AC-01.02", Result: Passed},
+				},
+			},
+			{
+				ControlID: "OSPS-BR-01",
+				Result:    Failed,
+				Assessments: []*Assessment{
+					{RequirementId: "OSPS-BR-01.01", Result: Failed},
+					{RequirementId: "OSPS-BR-01.02", Result: Failed},
+				},
+			},
+		},
+	}
+}
+
+func testWaiver(id, controlId, expires string, requirementIds ...string) layer3.Waiver {
+	return layer3.Waiver{
+		Id:             id,
+		ControlId:      controlId,
+		RequirementIds: requirementIds,
+		Justification:  "Accepted while the migration is in progress",
+		Approver:       layer3.Contact{Name: "Security Lead", Primary: true},
+		Expires:        layer3.Datetime(expires),
+	}
+}
+
+func TestApplyWaivers(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	results := waiverResults()
+	waivers := []layer3.Waiver{
+		testWaiver("W-1", "OSPS-AC-01", "2025-12-31T00:00:00Z"),
+		testWaiver("W-2", "OSPS-BR-01", "2025-01-01T00:00:00Z", "OSPS-BR-01.01"),
+		testWaiver("W-3", "OSPS-VM-01", "2025-12-31T00:00:00Z"),
+		{Id: "W-4", ControlId: "OSPS-BR-01", Target: "production", Expires: "2025-12-31T00:00:00Z"},
+	}
+
+	report, err := results.ApplyWaivers(waivers, WithWaiverTime(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waived := results.EvaluationSet[0].Assessments[0]
+	if waived.Result != Waived || waived.Waiver == nil || waived.Waiver.OriginalResult != Failed || waived.Waiver.Approver != "Security Lead" {
+		t.Errorf("expected the failure to be waived by W-1 with the original result kept, got %s with %+v", waived.Result, waived.Waiver)
+	}
+	if results.EvaluationSet[0].Result != Waived {
+		t.Errorf("expected the control result to be recalculated as Waived, got %s", results.EvaluationSet[0].Result)
+	}
+
+	expired := results.EvaluationSet[1].Assessments[0]
+	if expired.Result != Failed || expired.Waiver == nil || !expired.Waiver.Expired {
+		t.Errorf("expected the expired waiver to be flagged without changing the result, got %s with %+v", expired.Result, expired.Waiver)
+	}
+	if results.EvaluationSet[1].Assessments[1].Waiver != nil {
+		t.Error("expected the waiver for another requirement and the waiver for another target not to apply")
+	}
+	if len(report.Waived) != 1 || len(report.Expired) != 1 || len(report.Unused) != 2 || report.Unused[0] != "W-3" {
+		t.Errorf("unexpected report %+v", report)
+	}
+
+	// Applying the waivers later re-evaluates their expiry
+	report, err = results.ApplyWaivers(waivers, WithWaiverTime(now.AddDate(1, 0, 0)), WithWaiverTarget("production"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if waived.Result != Failed || !waived.Waiver.Expired {
+		t.Errorf("expected the expired waiver to restore the original result, got %s with %+v", waived.Result, waived.Waiver)
+	}
+	if results.EvaluationSet[0].Result != Failed {
+		t.Errorf("expected the control result to be Failed again, got %s", results.EvaluationSet[0].Result)
+	}
+	if len(report.Waived) != 0 || len(report.Expired) != 3 {
+		t.Errorf("expected all matching waivers to have expired, got %+v", report)
+	}
+}
+
+func TestApplyWaiversInvalidExpiry(t *testing.T) {
+	results := waiverResults()
+	_, err := results.ApplyWaivers([]layer3.Waiver{testWaiver("W-1", "OSPS-AC-01", "next year")})
+	if err == nil {
+		t.Fatal("expected an error for an invalid expiry")
+	}
+	if results.EvaluationSet[0].Assessments[0].Result != Failed {
+		t.Error("expected results to be unchanged when waivers are invalid")
+	}
+}
+
+func TestWaiverRoundTrip(t *testing.T) {
+	results := waiverResults()
+	if _, err := results.ApplyWaivers([]layer3.Waiver{testWaiver("W-1", "OSPS-AC-01", "2999-01-01T00:00:00Z")}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := yaml.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := &EvaluationResults{}
+	if err := yaml.Unmarshal(data, loaded); err != nil {
+		t.Fatal(err)
+	}
+	assessment := loaded.EvaluationSet[0].Assessments[0]
+	if assessment.Result != Waived || assessment.Waiver == nil || assessment.Waiver.WaiverId != "W-1" || assessment.Waiver.OriginalResult != Failed {
+		t.Errorf("expected the waiver to round trip with the assessment, got %s with %+v", assessment.Result, assessment.Waiver)
+	}
+}
+
+func TestApplyWaiversAfterAttestation(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	results := attestationResults()
+	waivers := []layer3.Waiver{testWaiver("W-1", "OSPS-GV-02", "2025-12-01T00:00:00Z")}
+	failed := Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "alice", AttestedOn: "2025-05-01T00:00:00Z", Decision: Failed}
+	passed := Attestation{ControlId: "OSPS-GV-02", RequirementId: "OSPS-GV-02.01", Attester: "bob", AttestedOn: "2025-05-02T00:00:00Z", Decision: Passed}
+
+	if _, err := results.ApplyAttestations([]Attestation{failed}, WithAttestationTime(now)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := results.ApplyWaivers(waivers, WithWaiverTime(now)); err != nil {
+		t.Fatal(err)
+	}
+	assessment := results.EvaluationSet[0].Assessments[0]
+	if assessment.Result != Waived || assessment.Waiver.OriginalResult != Failed {
+		t.Fatalf("expected the attested failure to be waived, got %s with %+v", assessment.Result, assessment.Waiver)
+	}
+
+	if _, err := results.ApplyAttestations([]Attestation{failed, passed}, WithAttestationTime(now)); err != nil {
+		t.Fatal(err)
+	}
+	if assessment.Result != Passed || assessment.Waiver != nil {
+		t.Errorf("expected the new attestation to replace the waived result, got %s with %+v", assessment.Result, assessment.Waiver)
+	}
+	report, err := results.ApplyWaivers(waivers, WithWaiverTime(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assessment.Result != Passed || assessment.Waiver != nil || len(report.Waived) != 0 {
+		t.Errorf("expected the passed assessment not to be waived again, got %s with %+v", assessment.Result, assessment.Waiver)
+	}
+	if results.EvaluationSet[0].Result != Passed {
+		t.Errorf("expected the control result to be recalculated, got %s", results.EvaluationSet[0].Result)
+	}
+}
@@ -0,0 +1,70 @@
+package render_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/ossf/gemara/layer2"
+	"github.com/ossf/gemara/render"
+)
+
+func ExampleMarkdown() {
+	catalog := layer2.Catalog{
+		Metadata: layer2.Metadata{
+			Id:      "EXP",
+			Title:   "Example Catalog",
+			Version: "0.1.0",
+		},
+		ControlFamilies: []layer2.ControlFamily{
+			{
+				Id:          "AC",
+				Title:       "Access Control",
+				Description: "Controls for access management",
+				Controls: []layer2.Control{
+					{
+						Id:        "EXP-AC-01",
+						Title:     "Require multi-factor authentication",
+						Objective: "Reduce the risk of account compromise.",
+						AssessmentRequirements: []layer2.AssessmentRequirement{
+							{
+								Id:            "EXP-AC-01.01",
+								Text:          "Collaborators MUST use multi-factor authentication.",
+								Applicability: []string{"Maturity Level 1"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	err := render.Markdown(os.Stdout, catalog)
+	if err != nil {
+		fmt.Printf("error rendering catalog: %v\n", err)
+	}
+	// Output:
+	// # Example Catalog
+	//
+	// - **ID:** EXP
+	// - **Version:** 0.1.0
+	//
+	// <a id="ac"></a>
+	//
+	// ## Access Control (AC)
+	//
+	// Controls for access management
+	//
+	// <a id="exp-ac-01"></a>
+	//
+	// ### EXP-AC-01: Require multi-factor authentication
+	//
+	// **Objective:** Reduce the risk of account compromise.
+	//
+	// #### Assessment Requirements
+	//
+	// <a id="exp-ac-01-01"></a>
+	//
+	// **EXP-AC-01.01:** Collaborators MUST use multi-factor authentication.
+	//
+	// - **Applicability:** Maturity Level 1
+}
@@ -0,0 +1,432 @@
+// Package render produces human-readable Markdown and standalone HTML documents from Gemara documents.
+package render
+
+import (
+	"embed"
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"regexp"
+	"strings"
+	textTemplate "text/template"
+	"unicode/utf8"
+
+	"github.com/ossf/gemara/layer1"
+	"github.com/ossf/gemara/layer2"
+	"github.com/ossf/gemara/layer3"
+	"github.com/ossf/gemara/layer4"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// Kind identifies the type of document being rendered and selects the template used to render it.
+type Kind string
+
+const (
+	// GuidanceKind is used for Layer 1 guidance documents
+	GuidanceKind Kind = "guidance"
+	// CatalogKind is used for Layer 2 control catalogs
+	CatalogKind Kind = "catalog"
+	// PolicyKind is used for Layer 3 policy documents
+	PolicyKind Kind = "policy"
+	// EvaluationKind is used for Layer 4 evaluation results
+	EvaluationKind Kind = "evaluation"
+	// CatalogDiffKind is used for changelogs between two versions of a Layer 2 control catalog
+	CatalogDiffKind Kind = "catalog-diff"
+	// GuidanceDiffKind is used for changelogs between two versions of a Layer 1 guidance document
+	GuidanceDiffKind Kind = "guidance-diff"
+	// ComparisonKind is used for comparisons between two Layer 4 evaluation runs
+	ComparisonKind Kind = "comparison"
+	// SummaryKind is used for summaries of Layer 4 evaluation results
+	SummaryKind Kind = "summary"
+)
+
+const (
+	markdownExt = "md"
+	htmlExt     = "html"
+	terminalExt = "txt"
+	htmlLayout  = "layout"
+)
+
+type renderOpts struct {
+	markdownTemplates map[Kind]string
+	htmlTemplates     map[Kind]string
+	htmlLayout        string
+	terminalTemplates map[Kind]string
+	noColor           bool
+}
+
+// Option defines an option to tune the behavior of the rendering methods.
+type Option func(opts *renderOpts)
+
+// WithMarkdownTemplate is an Option that replaces the default Markdown template for the given
+// document kind. The template is executed with the document as its data.
+func WithMarkdownTemplate(kind Kind, text string) Option {
+	return func(opts *renderOpts) {
+		if opts.markdownTemplates == nil {
+			opts.markdownTemplates = make(map[Kind]string)
+		}
+		opts.markdownTemplates[kind] = text
+	}
+}
+
+// WithHTMLTemplate is an Option that replaces the default HTML template for the given document kind.
+// The template must define the "title" and "content" templates, which are placed into the HTML layout.
+func WithHTMLTemplate(kind Kind, text string) Option {
+	return func(opts *renderOpts) {
+		if opts.htmlTemplates == nil {
+			opts.htmlTemplates = make(map[Kind]string)
+		}
+		opts.htmlTemplates[kind] = text
+	}
+}
+
+// WithHTMLLayout is an Option that replaces the default HTML layout shared by all document kinds.
+// The layout must define the "layout" template and should include the "title" and "content" templates.
+func WithHTMLLayout(text string) Option {
+	return func(opts *renderOpts) {
+		opts.htmlLayout = text
+	}
+}
+
+// WithTerminalTemplate is an Option that replaces the default terminal template for the given
+// document kind. The template may use the "color" function to highlight text.
+func WithTerminalTemplate(kind Kind, text string) Option {
+	return func(opts *renderOpts) {
+		if opts.terminalTemplates == nil {
+			opts.terminalTemplates = make(map[Kind]string)
+		}
+		opts.terminalTemplates[kind] = text
+	}
+}
+
+// WithoutColor is an Option that disables ANSI colors in terminal output,
+// for example when the output is not a terminal or NO_COLOR is set.
+func WithoutColor() Option {
+	return func(opts *renderOpts) {
+		opts.noColor = true
+	}
+}
+
+// Markdown renders the document as Markdown to the provided writer.
+// Supported documents are layer1.GuidanceDocument, layer2.Catalog, layer3.PolicyDocument,
+// layer4.EvaluationResults and []*layer4.ControlEvaluation, or pointers to them.
+// Changelogs can be rendered from a layer1.GuidanceDiff or a layer2.CatalogDiff, summaries
+// of the differences between evaluation runs from a layer4.Comparison, and evaluation
+// reports from a layer4.Summary.
+func Markdown(w io.Writer, document interface{}, opts ...Option) error {
+	options := renderOpts{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	kind, data, links, err := inspect(document)
+	if err != nil {
+		return err
+	}
+
+	text, ok := options.markdownTemplates[kind]
+	if !ok {
+		text, err = defaultTemplate(kind, markdownExt)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpl, err := textTemplate.New(string(kind)).Funcs(links.funcMap()).Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing %s markdown template: %w", kind, err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("error rendering %s markdown: %w", kind, err)
+	}
+	return nil
+}
+
+// HTML renders the document as a standalone HTML page to the provided writer.
+// Supported documents are the same as for Markdown, except for changelogs and comparisons.
+func HTML(w io.Writer, document interface{}, opts ...Option) error {
+	options := renderOpts{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	kind, data, links, err := inspect(document)
+	if err != nil {
+		return err
+	}
+
+	layout := options.htmlLayout
+	if layout == "" {
+		layout, err = defaultTemplate(htmlLayout, htmlExt)
+		if err != nil {
+			return err
+		}
+	}
+	text, ok := options.htmlTemplates[kind]
+	if !ok {
+		text, err = defaultTemplate(kind, htmlExt)
+		if err != nil {
+			return err
+		}
+	}
+
+	tmpl, err := htmlTemplate.New(htmlLayout).Funcs(htmlTemplate.FuncMap(links.funcMap())).Parse(layout)
+	if err != nil {
+		return fmt.Errorf("error parsing html layout template: %w", err)
+	}
+	if _, err = tmpl.New(string(kind)).Parse(text); err != nil {
+		return fmt.Errorf("error parsing %s html template: %w", kind, err)
+	}
+	if err := tmpl.ExecuteTemplate(w, htmlLayout, data); err != nil {
+		return fmt.Errorf("error rendering %s html: %w", kind, err)
+	}
+	return nil
+}
+
+// Terminal renders the document as plain text with ANSI colors to the provided writer.
+// Only layer4.Summary documents have a default terminal template.
+func Terminal(w io.Writer, document interface{}, opts ...Option) error {
+	options := renderOpts{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	kind, data, links, err := inspect(document)
+	if err != nil {
+		return err
+	}
+
+	text, ok := options.terminalTemplates[kind]
+	if !ok {
+		text, err = defaultTemplate(kind, terminalExt)
+		if err != nil {
+			return err
+		}
+	}
+
+	funcs := links.funcMap()
+	funcs["color"] = func(name, text string) string {
+		code, known := ansiCodes[name]
+		if options.noColor || !known {
+			return text
+		}
+		return code + text + ansiReset
+	}
+	tmpl, err := textTemplate.New(string(kind)).Funcs(funcs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("error parsing %s terminal template: %w", kind, err)
+	}
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("error rendering %s terminal output: %w", kind, err)
+	}
+	return nil
+}
+
+const ansiReset = "\x1b[0m"
+
+var ansiCodes = map[string]string{
+	"bold":    "\x1b[1m",
+	"red":     "\x1b[31m",
+	"green":   "\x1b[32m",
+	"yellow":  "\x1b[33m",
+	"magenta": "\x1b[35m",
+	"cyan":    "\x1b[36m",
+}
+
+func defaultTemplate(kind Kind, ext string) (string, error) {
+	content, err := templateFS.ReadFile(fmt.Sprintf("templates/%s.%s.tmpl", kind, ext))
+	if err != nil {
+		return "", fmt.Errorf("no default %s template for %s documents: %w", ext, kind, err)
+	}
+	return string(content), nil
+}
+
+// inspect determines the kind of the document and collects the identifiers needed for cross-linking.
+func inspect(document interface{}) (Kind, interface{}, linker, error) {
+	switch doc := document.(type) {
+	case layer1.GuidanceDocument:
+		return inspect(&doc)
+	case *layer1.GuidanceDocument:
+		links := newLinker(doc.Metadata.Id)
+		for _, ref := range doc.Metadata.MappingReferences {
+			links.references[ref.Id] = ref.Url
+		}
+		for _, category := range doc.Categories {
+			links.addLocal(category.Id)
+			for _, guideline := range category.Guidelines {
+				links.addLocal(guideline.Id)
+				for _, part := range guideline.GuidelineParts {
+					links.addLocal(part.Id)
+				}
+			}
+		}
+		for _, principle := range doc.Principles {
+			links.addLocal(principle.Id)
+		}
+		return GuidanceKind, doc, links, nil
+	case layer1.GuidanceDiff:
+		return inspect(&doc)
+	case *layer1.GuidanceDiff:
+		return GuidanceDiffKind, doc, newLinker(doc.DocumentId), nil
+	case layer2.Catalog:
+		return inspect(&doc)
+	case *layer2.Catalog:
+		links := newLinker(doc.Metadata.Id)
+		for _, ref := range doc.Metadata.MappingReferences {
+			links.references[ref.Id] = ref.Url
+		}
+		for _, family := range doc.ControlFamilies {
+			for _, control := range family.Controls {
+				links.addLocal(control.Id)
+				for _, requirement := range control.AssessmentRequirements {
+					links.addLocal(requirement.Id)
+				}
+			}
+		}
+		for _, threat := range doc.Threats {
+			links.addLocal(threat.Id)
+		}
+		for _, capability := range doc.Capabilities {
+			links.addLocal(capability.Id)
+		}
+		return CatalogKind, doc, links, nil
+	case layer2.CatalogDiff:
+		return inspect(&doc)
+	case *layer2.CatalogDiff:
+		return CatalogDiffKind, doc, newLinker(doc.CatalogId), nil
+	case layer3.PolicyDocument:
+		return inspect(&doc)
+	case *layer3.PolicyDocument:
+		links := newLinker(doc.Metadata.Id)
+		for _, ref := range doc.Metadata.MappingReferences {
+			links.references[ref.Id] = ref.Url
+		}
+		return PolicyKind, doc, links, nil
+	case layer4.EvaluationResults:
+		return inspect(&doc)
+	case *layer4.EvaluationResults:
+		doc = withoutNilEntries(doc)
+		links := newLinker("")
+		for _, evaluation := range doc.EvaluationSet {
+			links.addLocal(evaluation.ControlID)
+			for _, assessment := range evaluation.Assessments {
+				links.addLocal(assessment.RequirementId)
+			}
+		}
+		return EvaluationKind, doc, links, nil
+	case layer4.Comparison:
+		return inspect(&doc)
+	case *layer4.Comparison:
+		return ComparisonKind, doc, newLinker(""), nil
+	case layer4.Summary:
+		return inspect(&doc)
+	case *layer4.Summary:
+		return SummaryKind, doc, newLinker(""), nil
+	case []*layer4.ControlEvaluation:
+		return inspect(&layer4.EvaluationResults{EvaluationSet: doc})
+	default:
+		return "", nil, linker{}, fmt.Errorf("unsupported document type for rendering: %T", document)
+	}
+}
+
+// withoutNilEntries returns a copy of the results without null control evaluations and assessments,
+// such as those loaded from a results file with empty list entries.
+func withoutNilEntries(results *layer4.EvaluationResults) *layer4.EvaluationResults {
+	copied := *results
+	copied.EvaluationSet = nil
+	for _, evaluation := range results.EvaluationSet {
+		if evaluation == nil {
+			continue
+		}
+		evaluationCopy := *evaluation
+		evaluationCopy.Assessments = nil
+		for _, assessment := range evaluation.Assessments {
+			if assessment != nil {
+				evaluationCopy.Assessments = append(evaluationCopy.Assessments, assessment)
+			}
+		}
+		copied.EvaluationSet = append(copied.EvaluationSet, &evaluationCopy)
+	}
+	return &copied
+}
+
+// linker resolves identifiers to anchors within the rendered document or to external mapping references.
+type linker struct {
+	documentId string
+	references map[string]string
+	local      map[string]bool
+}
+
+func newLinker(documentId string) linker {
+	return linker{
+		documentId: documentId,
+		references: make(map[string]string),
+		local:      make(map[string]bool),
+	}
+}
+
+func (l linker) addLocal(id string) {
+	if id != "" {
+		l.local[id] = true
+	}
+}
+
+// href returns the link target for an entry of a mapping to the given reference.
+// Entries defined in the rendered document link to their anchor, and entries of known mapping
+// references link to the reference URL. An empty string is returned when no link is available.
+func (l linker) href(referenceId, entryId string) string {
+	_, external := l.references[referenceId]
+	if l.local[entryId] && (referenceId == l.documentId || !external) {
+		return "#" + anchor(entryId)
+	}
+	return l.references[referenceId]
+}
+
+// localHref returns the anchor for an identifier defined in the rendered document, or an empty string.
+func (l linker) localHref(id string) string {
+	if l.local[id] {
+		return "#" + anchor(id)
+	}
+	return ""
+}
+
+func (l linker) funcMap() map[string]interface{} {
+	return map[string]interface{}{
+		"anchor":    anchor,
+		"oneline":   oneline,
+		"href":      l.href,
+		"localHref": l.localHref,
+		"join":      strings.Join,
+		"trim":      strings.TrimSpace,
+		"cell":      cell,
+		"width":     width,
+	}
+}
+
+var nonAnchorChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// anchor converts an identifier into a value that is safe to use as an HTML id and Markdown link fragment.
+func anchor(id string) string {
+	return strings.Trim(nonAnchorChars.ReplaceAllString(strings.ToLower(id), "-"), "-")
+}
+
+// oneline collapses all whitespace, such as newlines in YAML block scalars, into single spaces.
+func oneline(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// width returns the length of the longest group name, or of the header if it is longer, for aligning columns.
+func width(groups []layer4.GroupSummary, header string) int {
+	longest := utf8.RuneCountInString(header)
+	for _, group := range groups {
+		longest = max(longest, utf8.RuneCountInString(group.Name))
+	}
+	return longest
+}
+
+// cell prepares text for use in a Markdown table cell.
+func cell(text string) string {
+	return strings.ReplaceAll(oneline(text), "|", `\|`)
+}
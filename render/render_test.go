@@ -0,0 +1,486 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ossf/gemara/layer1"
+	"github.com/ossf/gemara/layer2"
+	"github.com/ossf/gemara/layer3"
+	"github.com/ossf/gemara/layer4"
+)
+
+func testGuidance() layer1.GuidanceDocument {
+	return layer1.GuidanceDocument{
+		Metadata: layer1.Metadata{
+			Id:      "EXP",
+			Title:   "Example Guidance",
+			Version: "1.0.0",
+			Author:  "Example Author",
+			MappingReferences: []layer1.MappingReference{
+				{
+					Id:      "NIST-800-53",
+					Title:   "NIST SP 800-53r5",
+					Version: "rev5",
+					Url:     "https://csrc.nist.gov/pubs/sp/800/53/r5/upd1/final",
+				},
+			},
+		},
+		Categories: []layer1.Category{
+			{
+				Id:          "DET",
+				Title:       "Detective",
+				Description: "Detection and Continuous Improvement",
+				Guidelines: []layer1.Guideline{
+					{
+						Id:        "EXP-DET-1",
+						Title:     "Human Feedback Loop",
+						Objective: "Collect and act on feedback.",
+						GuidelineMappings: []layer1.Mapping{
+							{
+								ReferenceId: "NIST-800-53",
+								Entries:     []layer1.MappingEntry{{ReferenceId: "CA-7", Strength: 7}},
+							},
+						},
+						PrincipleMappings: []layer1.Mapping{
+							{
+								ReferenceId: "EXP",
+								Entries:     []layer1.MappingEntry{{ReferenceId: "TIMELINESS", Strength: 7}},
+							},
+						},
+						SeeAlso: []string{"EXP-DET-1(1)", "EXP-PREV-5"},
+					},
+					{
+						Id:              "EXP-DET-1(1)",
+						Title:           "Feedback Review",
+						BaseGuidelineID: "EXP-DET-1",
+					},
+				},
+			},
+		},
+		Principles: []layer1.Principle{
+			{
+				Id:          "TIMELINESS",
+				Title:       "Timeliness",
+				Description: "Issues are acted upon quickly.",
+			},
+		},
+	}
+}
+
+func testCatalog(t *testing.T) *layer2.Catalog {
+	catalog := &layer2.Catalog{}
+	require.NoError(t, catalog.LoadFile("../layer2/test-data/good-ccc.yaml"))
+	return catalog
+}
+
+func testCatalogDiff(t *testing.T) layer2.CatalogDiff {
+	updated := testCatalog(t)
+	updated.Metadata.Version = "2.0.0"
+	control := &updated.ControlFamilies[0].Controls[0]
+	control.Title = "Require Encrypted Requests"
+	control.AssessmentRequirements[0].Applicability = []string{"tlp_green", "tlp_amber", "tlp_red"}
+	control.ThreatMappings[0].Entries[0].Strength = 9
+	return testCatalog(t).Diff(updated)
+}
+
+func testGuidanceDiff() layer1.GuidanceDiff {
+	updated := testGuidance()
+	updated.Metadata.Version = "2.0.0"
+	guideline := &updated.Categories[0].Guidelines[0]
+	guideline.Objective = "Collect, review and act on feedback."
+	guideline.GuidelineMappings[0].Entries[0].Strength = 9
+	updated.Categories[0].Guidelines[1].Id = "EXP-DET-1(2)"
+	original := testGuidance()
+	return original.Diff(&updated)
+}
+
+func testPolicy() layer3.PolicyDocument {
+	return layer3.PolicyDocument{
+		Metadata: layer3.Metadata{
+			Id:        "POL-1",
+			Title:     "Cloud Storage Policy",
+			Objective: "Protect data stored in the cloud.",
+			Version:   "1.0.0",
+			MappingReferences: []layer3.MappingReference{
+				{Id: "FINOS-CCC", Title: "FINOS Cloud Control Catalog", Version: "1.0", Url: "https://www.finos.org/common-cloud-controls-project"},
+			},
+		},
+		Contacts: layer3.Contacts{
+			Author: layer3.Contact{Name: "Policy Author", Primary: true},
+		},
+		Scope: layer3.Scope{Technologies: []string{"object-storage"}},
+		ControlReferences: []layer3.Mapping{
+			{
+				ReferenceId: "FINOS-CCC",
+				ControlModifications: []layer3.ControlModifier{
+					{TargetId: "CCC.C01", ModType: "exclude", ModificationRationale: "Handled | elsewhere"},
+				},
+			},
+		},
+	}
+}
+
+func testEvaluationResults() layer4.EvaluationResults {
+	return layer4.EvaluationResults{
+		EvaluationSet: []*layer4.ControlEvaluation{
+			{
+				ControlID: "CCC.C01",
+				Result:    layer4.Failed,
+				Message:   "TLS 1.0 is enabled",
+				Assessments: []*layer4.Assessment{
+					{
+						RequirementId:  "CCC.C01.TR01",
+						Description:    "Traffic must be encrypted",
+						Applicability:  []string{"tlp_clear"},
+						Result:         layer4.Failed,
+						Message:        "TLS 1.0 is enabled",
+						Recommendation: "Disable legacy TLS versions",
+					},
+				},
+			},
+		},
+	}
+}
+
+func testComparison() layer4.Comparison {
+	previous := testEvaluationResults()
+	current := testEvaluationResults()
+	current.EvaluationSet[0].Result = layer4.Passed
+	current.EvaluationSet[0].Message = "TLS 1.2 or later is enforced"
+	current.EvaluationSet[0].Assessments[0].Result = layer4.Passed
+	current.EvaluationSet[0].Assessments[0].Message = "TLS 1.2 or later is enforced"
+	return layer4.Compare(&previous, &current)
+}
+
+func testSummary() layer4.Summary {
+	results := testEvaluationResults()
+	return results.Summarize(nil)
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     interface{}
+		wantContains []string
+	}{
+		{
+			name:     "Guidance",
+			document: testGuidance(),
+			wantContains: []string{
+				"# Example Guidance",
+				`<a id="exp-det-1"></a>`,
+				"[CA-7](https://csrc.nist.gov/pubs/sp/800/53/r5/upd1/final)",
+				"[TIMELINESS](#timeliness)",
+				"**See Also:** [EXP-DET-1(1)](#exp-det-1-1), EXP-PREV-5",
+				"**Enhancement of:** [EXP-DET-1](#exp-det-1)",
+				"## Principles",
+			},
+		},
+		{
+			name:     "Catalog",
+			document: testCatalog(t),
+			wantContains: []string{
+				"# FINOS Cloud Control Catalog",
+				`<a id="ccc-c01"></a>`,
+				"### CCC.C01: Prevent Unencrypted Requests",
+				`<a id="ccc-c01-tr01"></a>`,
+				"- **Applicability:** tlp_clear, tlp_green, tlp_amber, tlp_red",
+				"| tlp_clear | TLP:Clear | Information may be shared without restriction. |",
+			},
+		},
+		{
+			name:     "Policy",
+			document: testPolicy(),
+			wantContains: []string{
+				"# Cloud Storage Policy",
+				"- **Author:** Policy Author — primary contact",
+				"- **Technologies:** object-storage",
+				"### [FINOS-CCC](https://www.finos.org/common-cloud-controls-project)",
+				`| CCC.C01 | exclude | Handled \| elsewhere |`,
+			},
+		},
+		{
+			name:     "Evaluation",
+			document: testEvaluationResults(),
+			wantContains: []string{
+				"| [CCC.C01](#ccc-c01) | Failed | TLS 1.0 is enabled |",
+				`<a id="ccc-c01-tr01"></a>`,
+				"- **Recommendation:** Disable legacy TLS versions",
+			},
+		},
+		{
+			name:     "CatalogDiff",
+			document: testCatalogDiff(t),
+			wantContains: []string{
+				"# FINOS-CCC Changelog",
+				"Changes from version unversioned to 2.0.0.",
+				"| CCC.C01 | Data Protection | modified (renamed) |",
+				`- **title:** "Prevent Unencrypted Requests" → "Require Encrypted Requests"`,
+				"  - **Applicability removed:** tlp_clear",
+				"- CCC: CCC.TH02 modified (strength 7 → 9)",
+			},
+		},
+		{
+			name:     "GuidanceDiff",
+			document: testGuidanceDiff(),
+			wantContains: []string{
+				"# EXP Changelog",
+				"Changes from version 1.0.0 to 2.0.0.",
+				"| EXP-DET-1 | DET | modified |",
+				"| EXP-DET-1(2) | DET | renumbered (from EXP-DET-1(1)) |",
+				`- **objective:** "Collect and act on feedback." → "Collect, review and act on feedback."`,
+				"- NIST-800-53: CA-7 modified (strength 7 → 9)",
+			},
+		},
+		{
+			name:     "Comparison",
+			document: testComparison(),
+			wantContains: []string{
+				"**2 newly passing, 2 result changes**",
+				"## Newly Passing",
+				"| CCC.C01 / CCC.C01.TR01 | Failed | Passed | TLS 1.2 or later is enforced |",
+			},
+		},
+		{
+			name:     "Summary",
+			document: testSummary(),
+			wantContains: []string{
+				"**1 assessments:** 0 passed, 1 failed",
+				"| CCC | 0 | 1 | 0 | 0 | 0 | 0 | 0 |",
+				"| tlp_clear | 0 | 1 | 0 | 0 | 0 | 0 | 0 |",
+				"### CCC.C01.TR01",
+				"- **Recommendation:** Disable legacy TLS versions",
+			},
+		},
+		{
+			name:     "ControlEvaluationSlice",
+			document: testEvaluationResults().EvaluationSet,
+			wantContains: []string{
+				"| [CCC.C01](#ccc-c01) | Failed | TLS 1.0 is enabled |",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, Markdown(&buf, tt.document))
+			for _, want := range tt.wantContains {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tests := []struct {
+		name         string
+		document     interface{}
+		wantContains []string
+	}{
+		{
+			name:     "Guidance",
+			document: testGuidance(),
+			wantContains: []string{
+				"<title>Example Guidance</title>",
+				`<article id="exp-det-1">`,
+				`<a href="https://csrc.nist.gov/pubs/sp/800/53/r5/upd1/final">CA-7</a>`,
+				`<a href="#exp-det-1-1">EXP-DET-1(1)</a>`,
+			},
+		},
+		{
+			name:     "Catalog",
+			document: testCatalog(t),
+			wantContains: []string{
+				"<title>FINOS Cloud Control Catalog</title>",
+				`<article id="ccc-c01">`,
+				`<div id="ccc-c01-tr01">`,
+			},
+		},
+		{
+			name:     "Policy",
+			document: testPolicy(),
+			wantContains: []string{
+				"<title>Cloud Storage Policy</title>",
+				`<a href="https://www.finos.org/common-cloud-controls-project">FINOS-CCC</a>`,
+			},
+		},
+		{
+			name:     "Evaluation",
+			document: testEvaluationResults(),
+			wantContains: []string{
+				`<a href="#ccc-c01">CCC.C01</a>`,
+				`<span class="result-failed">Failed</span>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, HTML(&buf, tt.document))
+			assert.Contains(t, buf.String(), "<!DOCTYPE html>")
+			for _, want := range tt.wantContains {
+				assert.Contains(t, buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestLoadedEvaluationResults(t *testing.T) {
+	results := &layer4.EvaluationResults{}
+	require.NoError(t, results.LoadFile("../layer4/test-data/pvtr-baseline-scan.yaml"))
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, results))
+	assert.Contains(t, buf.String(), "- **Steps Executed:** 1 of 1")
+	assert.NotContains(t, buf.String(), "of 0")
+
+	buf.Reset()
+	require.NoError(t, HTML(&buf, results))
+	assert.Contains(t, buf.String(), "<li><strong>Steps Executed:</strong> 1 of 1</li>")
+	assert.NotContains(t, buf.String(), "of 0")
+}
+
+func TestEvaluationResultsWithNullEntries(t *testing.T) {
+	results := &layer4.EvaluationResults{
+		EvaluationSet: []*layer4.ControlEvaluation{
+			nil,
+			{ControlID: "OSPS-AC-01", Result: layer4.Passed, Assessments: []*layer4.Assessment{nil, {RequirementId: "OSPS-AC-01.01", Result: layer4.Passed}}},
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, results))
+	assert.Contains(t, buf.String(), "### OSPS-AC-01.01")
+
+	buf.Reset()
+	require.NoError(t, HTML(&buf, results))
+	assert.Contains(t, buf.String(), "OSPS-AC-01.01")
+	assert.Len(t, results.EvaluationSet, 2, "expected the results not to be modified")
+}
+
+func TestWaivedSummary(t *testing.T) {
+	results := testEvaluationResults()
+	results.EvaluationSet[0].Assessments[0].Result = layer4.Waived
+	summary := results.Summarize(nil)
+
+	var buf bytes.Buffer
+	require.NoError(t, Markdown(&buf, summary))
+	assert.Contains(t, buf.String(), "| Family | Passed | Failed | Needs Review | Not Applicable | Not Run | Unknown | Waived |")
+	assert.Contains(t, buf.String(), "| CCC | 0 | 0 | 0 | 0 | 0 | 0 | 1 |")
+	assert.Contains(t, buf.String(), "| tlp_clear | 0 | 0 | 0 | 0 | 0 | 0 | 1 |")
+
+	buf.Reset()
+	require.NoError(t, Terminal(&buf, summary, WithoutColor()))
+	assert.Contains(t, buf.String(), "Unknown    Waived")
+	assert.Contains(t, buf.String(), "CCC            0         0         0         0         0         0         1")
+}
+
+func TestTerminal(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Terminal(&buf, testSummary()))
+	assert.Contains(t, buf.String(), "\x1b[31m1 failed\x1b[0m")
+	assert.Contains(t, buf.String(), "Family    Passed")
+
+	buf.Reset()
+	require.NoError(t, Terminal(&buf, testSummary(), WithoutColor()))
+	assert.NotContains(t, buf.String(), "\x1b[")
+	assert.Contains(t, buf.String(), "CCC            0         1")
+	assert.Contains(t, buf.String(), "  Recommendation: Disable legacy TLS versions")
+
+	buf.Reset()
+	require.NoError(t, Terminal(&buf, testSummary(), WithoutColor(), WithTerminalTemplate(SummaryKind, `{{ color "red" "failed" }}`)))
+	assert.Equal(t, "failed", buf.String())
+
+	assert.Error(t, Terminal(&buf, testCatalog(t)), "catalogs have no default terminal template")
+}
+
+func TestTemplateOverrides(t *testing.T) {
+	var buf bytes.Buffer
+	err := Markdown(&buf, testGuidance(), WithMarkdownTemplate(GuidanceKind, "{{ .Metadata.Id }}: {{ anchor .Metadata.Title }}"))
+	require.NoError(t, err)
+	assert.Equal(t, "EXP: example-guidance", buf.String())
+
+	buf.Reset()
+	err = HTML(&buf, testGuidance(),
+		WithHTMLLayout(`{{ define "layout" }}<main>{{ template "content" . }}</main>{{ end }}`),
+		WithHTMLTemplate(GuidanceKind, `{{ define "title" }}{{ end }}{{ define "content" }}{{ .Metadata.Title }}{{ end }}`),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "<main>Example Guidance</main>", buf.String())
+
+	buf.Reset()
+	err = Markdown(&buf, testGuidance(), WithMarkdownTemplate(GuidanceKind, "{{ .Missing "))
+	assert.Error(t, err)
+}
+
+func TestUnsupportedDocument(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, Markdown(&buf, "not a document"))
+	assert.Error(t, HTML(&buf, 42))
+	assert.Error(t, HTML(&buf, testCatalogDiff(t)))
+	assert.Error(t, HTML(&buf, testGuidanceDiff()))
+	assert.Error(t, HTML(&buf, testComparison()))
+}
+
+func TestRedactedOutputs(t *testing.T) {
+	evaluation := &layer4.ControlEvaluation{ControlID: "OSPS-AC-01"}
+	assessment := evaluation.AddAssessment("OSPS-AC-01.01", "Require MFA", []string{"Maturity Level 1"}, []layer4.AssessmentStep{
+		func(data interface{}, changes map[string]*layer4.Change) (layer4.Result, string) {
+			changes["rotate-token"].Apply("token-hunter2", nil, nil)
+			return layer4.Failed, "password hunter2 was accepted"
+		},
+	})
+	assessment.NewChange("rotate-token", "ossf", "Rotate the token", nil,
+		func(interface{}) (interface{}, error) { return nil, nil },
+		func(interface{}) error { return nil },
+	)
+	denyAll := layer4.ChangeAuthorizerFunc(func(request layer4.ChangeRequest) (bool, string) {
+		return false, "not allowed on " + request.TargetName
+	})
+	redactor := layer4.NewRedactor(layer4.WithRedactedValues(regexp.MustCompile(`hunter2`)))
+	evaluation.Evaluate(nil, []string{"Maturity Level 1"}, true, layer4.WithChangeAuthorizer(denyAll), layer4.WithRedactor(redactor))
+	require.Equal(t, "not allowed on token-hunter2", assessment.Changes["rotate-token"].Denial)
+	results := &layer4.EvaluationResults{EvaluationSet: []*layer4.ControlEvaluation{evaluation}}
+
+	outputs := map[string]string{}
+	sarifLog, err := results.ToSARIF(nil)
+	require.NoError(t, err)
+	data, err := json.Marshal(sarifLog)
+	require.NoError(t, err)
+	outputs["SARIF"] = string(data)
+	data, err = xml.Marshal(results.ToJUnit("gemara"))
+	require.NoError(t, err)
+	outputs["JUnit"] = string(data)
+	data, err = json.Marshal(results)
+	require.NoError(t, err)
+	outputs["JSON"] = string(data)
+	for name, document := range map[string]interface{}{
+		"evaluation": results,
+		"summary":    results.Summarize(nil),
+		"comparison": layer4.Compare(&layer4.EvaluationResults{}, results),
+	} {
+		var buf bytes.Buffer
+		require.NoError(t, Markdown(&buf, document))
+		outputs[name+" Markdown"] = buf.String()
+	}
+	var buf bytes.Buffer
+	require.NoError(t, HTML(&buf, results))
+	outputs["evaluation HTML"] = buf.String()
+	buf.Reset()
+	require.NoError(t, Terminal(&buf, results.Summarize(nil), WithoutColor()))
+	outputs["summary terminal"] = buf.String()
+
+	for name, output := range outputs {
+		assert.Contains(t, output, layer4.DefaultReplacement, "expected the %s output to include the redacted message", name)
+		assert.NotContains(t, output, "hunter2", "expected the %s output to be redacted", name)
+	}
+}
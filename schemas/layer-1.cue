@@ -9,6 +9,9 @@ package schemas
 	"front-matter"?: string @go(FrontMatter) @yaml("front-matter,omitempty")
 	"categories"?: [...#Category] @go(Categories)
 
+	// Foundational tenets that guidelines in this or other documents map to
+	principles?: [...#Principle] @go(Principles)
+
 	// For inheriting from other guidance documents to create tailored documents/baselines
 	"imported-guidelines"?: [...#Mapping] @go(ImportedGuidelines) @yaml("imported-guidelines,omitempty")
 	"imported-principles"?: [...#Mapping] @go(ImportedPrinciples) @yaml("imported-principles,omitempty")
@@ -52,6 +55,18 @@ package schemas
 	guidelines?: [...#Guideline]
 }
 
+// Principle represents a foundational tenet or value that guidelines are intended to uphold
+#Principle: {
+	id:          string
+	title:       string
+	description: string
+
+	rationale?: #Rationale @go(Rationale,optional=nillable)
+
+	// Corresponds to the resource ids in metadata to map to external unstructured resources
+	"external-references"?: [...string] @go(ExternalReferences) @yaml("external-references,omitempty")
+}
+
 // Rationale provides contextual information to help with development and understanding of
 // guideline intent.
 #Rationale: {
@@ -119,6 +119,25 @@ import "time"
 	recommendations?: [...string]
 }
 
+// Waivers accept the risk of failing evaluation results until they expire
+#WaiverDocument: {
+	metadata: #Metadata
+	waivers: [...#Waiver]
+}
+
+#Waiver: {
+	id:           string
+	"control-id": string @go(ControlId)
+	// The assessment requirements covered by the waiver. All requirements of the control are covered if none are listed.
+	"requirement-ids"?: [...string] @go(RequirementIds) @yaml("requirement-ids",omitempty)
+	// The evaluation target covered by the waiver, such as a repository or account name. All targets are covered if omitted.
+	target?:       string
+	justification: string
+	approver:      #Contact
+	"approved-on"?: #Datetime @go(ApprovedOn) @yaml("approved-on",omitempty)
+	expires:        #Datetime
+}
+
 #Contact: {
 	// The contact person's name.
 	name: string
@@ -13,6 +13,7 @@ import "time"
 	result:            #Result
 	message:           string
 	"corrupted-state": bool @go(CorruptedState)
+	"corrupted-changes"?: [...#CorruptedChange] @go(CorruptedChanges)
 	assessments: [...#Assessment]
 }
 
@@ -23,25 +24,92 @@ import "time"
 	result:      #Result
 	message:     string
 	steps: [...#AssessmentStep]
+	"step-details"?: [...#StepInfo] @go(StepDetails)
 	"steps-executed"?: int @go(StepsExecuted)
+	"step-durations"?: [...number] @go(StepDurations)
 	"start":           #Datetime
 	"end"?:            #Datetime
 	value?:            _
 	changes?: {[string]: #Change}
+	evidence?: [...#Evidence]
 	recommendation?: string
+	waiver?:         #AssessmentWaiver
+	attestation?:    #AssessmentAttestation
+}
+
+// Attestation records a manual decision on an assessment that needs review
+#Attestation: {
+	"control-id":     string @go(ControlId)
+	"requirement-id": string @go(RequirementId)
+	attester:         string
+	"attested-on":    #Datetime @go(AttestedOn)
+	evidence?: [...string]
+	decision:  "Passed" | "Failed" | "Not Applicable"
+	comment?:  string
+	expires?:  #Datetime
+}
+
+#AssessmentAttestation: {
+	#Attestation
+	"automated-result": #Result @go(AutomatedResult)
+}
+
+// AssessmentWaiver records the waiver that was applied to an assessment result
+#AssessmentWaiver: {
+	"waiver-id":       string @go(WaiverId)
+	justification:     string
+	approver:          string
+	expires:           #Datetime
+	expired:           bool
+	"original-result": #Result @go(OriginalResult)
+}
+
+// Evidence is an artefact collected during an assessment, stored inline or referenced by its URI
+#Evidence: {
+	name:           string
+	"media-type":   string @go(MediaType)
+	digest:         =~"^sha256:[a-f0-9]{64}$"
+	size:           int
+	"collected-at": #Datetime @go(CollectedAt)
+	source?:        string
+	content?:       string
+	encoding?:      "base64"
+	uri?:           string @go(URI)
 }
 
 #AssessmentStep: string
 
+#StepInfo: {
+	id:           string
+	description?: string
+	version?:     string
+}
+
 #Change: {
-	"target-name":    string @go(TargetName)
-	description:      string
-	"target-object"?: _ @go(TargetObject)
-	applied?:         bool
-	reverted?:        bool
-	error?:           string
+	"target-name":      string @go(TargetName)
+	description:        string
+	"target-object"?:   _ @go(TargetObject)
+	applied?:           bool
+	reverted?:          bool
+	allowed?:           bool
+	planned?:           bool
+	denial?:            string
+	error?:             string
+	"applied-at"?:      #Datetime @go(AppliedAt)
+	"reverted-at"?:     #Datetime @go(RevertedAt)
+	"apply-attempts"?:  int @go(ApplyAttempts)
+	"revert-attempts"?: int @go(RevertAttempts)
+}
+
+#CorruptedChange: {
+	"requirement-id":  string @go(RequirementId)
+	"change-name":     string @go(ChangeName)
+	"target-name":     string @go(TargetName)
+	description:       string
+	error?:            string
+	"revert-attempts": int @go(RevertAttempts)
 }
 
-#Result: "Not Run" | "Passed" | "Failed" | "Needs Review" | "Not Applicable" | "Unknown"
+#Result: "Not Run" | "Passed" | "Failed" | "Needs Review" | "Not Applicable" | "Unknown" | "Waived"
 
 #Datetime: time.Format("2006-01-02T15:04:05Z07:00") @go(Datetime,format="date-time")